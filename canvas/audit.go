@@ -0,0 +1,223 @@
+package canvas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord is one line of an audit log, see WithAuditLog
+type AuditRecord struct {
+	Time       time.Time       `json:"time"`
+	Canvas     string          `json:"canvas"`
+	Type       MessageType     `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`  // redacted, see WithAuditRedactor
+	PeerPID    int             `json:"peer_pid,omitempty"` // local clients, on Linux
+	PeerUID    *int            `json:"peer_uid,omitempty"` // local clients, on Linux
+	Remote     string          `json:"remote,omitempty"`   // address of network clients
+	Result     string          `json:"result"`             // AuditOK or AuditError
+	Response   json.RawMessage `json:"response,omitempty"` // redacted payload of the response
+	Error      string          `json:"error,omitempty"`    // "code: message" of failed requests
+	DurationMS float64         `json:"duration_ms"`
+}
+
+// Results of audited requests
+const (
+	AuditOK    = "ok"
+	AuditError = "error"
+)
+
+// Redactor returns what to record of the payload of a message of type t
+type Redactor func(t MessageType, payload json.RawMessage) json.RawMessage
+
+// redactedValue replaces the values removed by RedactSecrets
+const redactedValue = "[REDACTED]"
+
+// secretKeys are the parts of object keys whose values RedactSecrets
+// removes
+var secretKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "authorization", "credential"}
+
+// RedactSecrets is the default Redactor. It replaces the value of every
+// object member, at any depth, whose key mentions a password, secret,
+// token, API key, authorization or credential.
+func RedactSecrets(t MessageType, payload json.RawMessage) json.RawMessage {
+	var v any
+	if len(payload) == 0 || json.Unmarshal(payload, &v) != nil {
+		return payload
+	}
+	if !redact(v) {
+		return payload
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// RedactInput is a Redactor for apps whose typed text is itself
+// sensitive, such as passwords entered at a prompt. It applies
+// RedactSecrets and also replaces the text of send_input requests.
+func RedactInput(t MessageType, payload json.RawMessage) json.RawMessage {
+	payload = RedactSecrets(t, payload)
+	if t != MsgSendInput {
+		return payload
+	}
+	var input map[string]any
+	if json.Unmarshal(payload, &input) != nil {
+		return payload
+	}
+	if _, ok := input["text"]; !ok {
+		return payload
+	}
+	input["text"] = redactedValue
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redact removes secrets from a decoded JSON value in place and reports
+// whether it found any
+func redact(v any) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, member := range v {
+			if secretKey(k) {
+				v[k] = redactedValue
+				found = true
+			} else if redact(member) {
+				found = true
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			if redact(elem) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+func secretKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range secretKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// audited reports whether requests of type t are recorded: those that act
+// on the app and those of types registered with Handle or WithHandler,
+// which may, rather than reads or types nothing handles
+func (s *Server) audited(t MessageType) bool {
+	switch t {
+	case MsgSendKey, MsgSendInput, MsgClose:
+		return true
+	}
+	if _, builtin := builtinHandlers[t]; builtin {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.handlers[t] != nil
+}
+
+// auditLog appends records to a file, one JSON object per line
+type auditLog struct {
+	mu     sync.Mutex
+	f      *os.File
+	path   string
+	redact Redactor
+}
+
+// openAuditLog opens the audit log at path for appending, or returns nil
+// if path is empty
+func openAuditLog(path string, redact Redactor) (*auditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	f, err := os.OpenFile(abs, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if redact == nil {
+		redact = RedactSecrets
+	}
+	return &auditLog{f: f, path: abs, redact: redact}, nil
+}
+
+// write appends a record and flushes it to disk
+func (l *auditLog) write(rec *AuditRecord) error {
+	rec.Payload = l.redact(rec.Type, rec.Payload)
+	rec.Response = l.redact(rec.Type, rec.Response)
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+func (l *auditLog) close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.f.Close()
+}
+
+// auditMiddleware records the requests that control the app, with the
+// response the client finally gets
+func (s *Server) auditMiddleware(next HandlerFunc) HandlerFunc {
+	return func(req *Request) *Message {
+		if !s.audited(req.Type) {
+			return next(req)
+		}
+
+		start := time.Now()
+		resp := next(req)
+		rec := &AuditRecord{
+			Time:       start.UTC(),
+			Canvas:     s.id,
+			Type:       req.Type,
+			Payload:    req.Payload,
+			Result:     AuditOK,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if pid, uid, ok := peerCred(req.Conn); ok {
+			rec.PeerPID, rec.PeerUID = pid, &uid
+		} else if req.Conn != nil && req.Conn.RemoteAddr() != nil {
+			rec.Remote = req.Conn.RemoteAddr().String()
+		}
+		if resp.Type == MsgError {
+			rec.Result, rec.Error = AuditError, responseError(resp).Error()
+		} else {
+			rec.Response = resp.Payload
+		}
+
+		if err := s.audit.write(rec); err != nil {
+			s.reportError(fmt.Errorf("failed to write audit record: %w", err))
+		}
+		return resp
+	}
+}
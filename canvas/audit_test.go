@@ -0,0 +1,116 @@
+package canvas
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactors(t *testing.T) {
+	tests := []struct {
+		name    string
+		redact  Redactor
+		t       MessageType
+		payload string
+		want    string
+	}{
+		{"no secrets", RedactSecrets, "login", `{"user":"me"}`, `{"user":"me"}`},
+		{"nested secrets", RedactSecrets, "login",
+			`{"user":"me","auth":{"Password":"x","apiKey":"y"},"list":[{"token_id":1}]}`,
+			`{"auth":{"Password":"[REDACTED]","apiKey":"[REDACTED]"},"list":[{"token_id":"[REDACTED]"}],"user":"me"}`},
+		{"not JSON", RedactSecrets, "login", `nope`, `nope`},
+		{"input kept", RedactSecrets, MsgSendInput, `{"text":"hunter2"}`, `{"text":"hunter2"}`},
+		{"input redacted", RedactInput, MsgSendInput, `{"text":"hunter2"}`, `{"text":"[REDACTED]"}`},
+		{"keys kept", RedactInput, MsgSendKey, `{"key":"enter"}`, `{"key":"enter"}`},
+		{"secrets still redacted", RedactInput, "login", `{"secret":"x"}`, `{"secret":"[REDACTED]"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.redact(tt.t, json.RawMessage(tt.payload))
+			if !jsonEqual(got, []byte(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+func TestAuditAfterStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	started, release := make(chan struct{}), make(chan struct{})
+	s := newTestServer(t, nil,
+		WithAuditLog(path),
+		WithHandler("slow", func(req *Request) *Message {
+			close(started)
+			<-release
+			return req.Reply(MsgAck, nil)
+		}),
+	)
+
+	conn, err := net.Dial("unix", s.SocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte(`{"type":"slow"}` + "\n"))
+	<-started
+
+	// The request outlives the server but is still recorded.
+	s.Stop()
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), `"type":"slow"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no record of the request in %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAuditedTypes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	s := newTestServer(t, keyViewModel{"x"}, WithAuditLog(path), WithHandler("echo", func(req *Request) *Message {
+		return req.Reply(MsgAck, nil)
+	}))
+	c := NewClientWithSocket(s.SocketPath())
+
+	c.GetState()
+	c.SendKey("enter")
+	c.Call("echo", nil, nil)
+	c.Call("unknown", nil, nil)
+	s.Stop()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []MessageType
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec AuditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad record %q: %v", line, err)
+		}
+		got = append(got, rec.Type)
+	}
+	if want := []MessageType{MsgSendKey, "echo"}; !slices.Equal(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package canvas
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// keyViewModel reports a fixed mode and view and accepts keys
+type keyViewModel struct{ viewStateModel }
+
+func (m keyViewModel) HandleCanvasKey(key string, r rune) error { return nil }
+
+func TestBatch(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "batch", keyViewModel{"x"})
+	c := NewClient("batch")
+
+	getState, _ := NewMessage(MsgGetState, nil)
+	sendKey, _ := NewMessage(MsgSendKey, KeyPayload{Key: "enter"})
+	getView, _ := NewMessage(MsgGetView, nil)
+	unknown, _ := NewMessage("no_such_type", nil)
+	responses, err := c.Batch(getState, sendKey, getView, unknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []MessageType
+	for _, resp := range responses {
+		got = append(got, resp.Type)
+	}
+	want := []MessageType{MsgState, MsgAck, MsgView, MsgError}
+	if !slices.Equal(got, want) {
+		t.Errorf("got responses %v, want %v", got, want)
+	}
+
+	subscribe, _ := NewMessage(MsgSubscribe, nil)
+	if _, err := c.Batch(subscribe); err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("got %v for subscribe in a batch, want invalid_payload", err)
+	}
+}
+
+func TestBatchWrapped(t *testing.T) {
+	tempSocketDir(t)
+	s := startServer(t, "wrapped", nil)
+	a := &BubbleTeaAdapter{server: s, model: teaModel{}, logger: discardLogger(), loopReqs: make(chan loopRequest)}
+	s.SetModel(a)
+
+	// The batch runs inside the program's event loop.
+	cmds := runLoop(a)
+	snap, err := NewClient("wrapped").Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.View == nil {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	if cmd := <-cmds; cmd == nil {
+		t.Error("the program stops waiting for requests after a batch")
+	}
+}
+
+// serveOld answers get_state and get_view the way canvases did before
+// batches, and everything else with unknown_type
+func serveOld(t *testing.T, socket string) {
+	t.Helper()
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				enc := json.NewEncoder(conn)
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var msg Message
+					json.Unmarshal(scanner.Bytes(), &msg)
+					switch msg.Type {
+					case MsgGetState:
+						resp, _ := NewMessage(MsgState, StatePayload{Mode: "old"})
+						enc.Encode(resp)
+					case MsgGetView:
+						resp, _ := NewMessage(MsgView, ViewPayload{Content: "old view"})
+						enc.Encode(resp)
+					default:
+						enc.Encode(errorMessage("unknown_type", "unknown message type: "+string(msg.Type)))
+					}
+				}
+			}()
+		}
+	}()
+}
+
+func TestSnapshotWithoutBatch(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "old.sock")
+	serveOld(t, socket)
+
+	snap, err := NewClientWithSocket(socket).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.State == nil || snap.State.Mode != "old" || snap.View == nil || *snap.View != "old view" || snap.Err() != nil {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
@@ -1,51 +1,189 @@
 package canvas
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// closeTimeout bounds how long a close request waits for the program
+var closeTimeout = 5 * time.Second
+
 type BubbleTeaAdapter struct {
 	server *Server
 	model  tea.Model
+	logger *slog.Logger // the server's, see WithLogger
+
+	lastView string           // last rendered view, to detect changes
+	loopReqs chan loopRequest // work handed to the program, see runInLoop
+}
+
+// loopRequest carries work into the program's event loop, where the model
+// can be used safely
+type loopRequest struct {
+	fn    func(m *loopModel) any
+	reply chan any
 }
 
+// loopModel is the adapter as seen from inside the event loop, between
+// two updates
+type loopModel struct {
+	*BubbleTeaAdapter
+	quit bool // set by a close the model did not veto
+}
+
+// Wrap adds canvas support to a Bubble Tea model when OPENCODE_CANVAS or
+// CANVAS_ID is set, and returns the model unchanged otherwise or if the
+// canvas cannot start
 func Wrap(canvasID string, model tea.Model) tea.Model {
-	if os.Getenv("OPENCODE_CANVAS") == "" && os.Getenv("CANVAS_ID") == "" {
-		return model
+	return WrapWithOptions(canvasID, model)
+}
+
+// WrapWithOptions is Wrap with server options. An error starting the
+// canvas is passed to the WithErrorHandler callback, if any.
+func WrapWithOptions(canvasID string, model tea.Model, opts ...ServerOption) tea.Model {
+	wrapped, err := WrapE(canvasID, model, opts...)
+	if err != nil {
+		o := newServerOptions(opts)
+		o.logger.Error("canvas failed to start", "id", canvasID, "err", err)
+		if o.onError != nil {
+			o.onError(err)
+		}
 	}
+	return wrapped
+}
 
+// WrapE is WrapWithOptions returning the error that kept the canvas from
+// starting, together with the unwrapped model
+func WrapE(canvasID string, model tea.Model, opts ...ServerOption) (tea.Model, error) {
+	if !newServerOptions(opts).enabled() {
+		return model, nil
+	}
+
+	// An explicit CANVAS_ID must be honored exactly; the app's default ID
+	// is suffixed so several instances can run side by side.
 	id := canvasID
 	if envID := os.Getenv("CANVAS_ID"); envID != "" {
 		id = envID
+	} else {
+		opts = append([]ServerOption{WithAutoSuffix()}, opts...)
 	}
 
-	server, err := NewServer(id)
+	// Containers and remote hosts can opt into network access without
+	// code changes.
+	if token := os.Getenv("CANVAS_TOKEN"); token != "" {
+		opts = append([]ServerOption{WithToken(token)}, opts...)
+	}
+	if addr := os.Getenv("CANVAS_METRICS_ADDR"); addr != "" {
+		opts = append([]ServerOption{WithMetricsAddr(addr)}, opts...)
+	}
+	if path := os.Getenv("CANVAS_AUDIT_LOG"); path != "" {
+		opts = append([]ServerOption{WithAuditLog(path)}, opts...)
+	}
+	if os.Getenv("CANVAS_AUDIT_REDACT_INPUT") == "1" {
+		opts = append([]ServerOption{WithAuditRedactor(RedactInput)}, opts...)
+	}
+	if listen := os.Getenv("CANVAS_LISTEN"); listen != "" {
+		for _, addr := range strings.Split(listen, ",") {
+			opts = append([]ServerOption{WithListen(strings.TrimSpace(addr))}, opts...)
+		}
+	}
+
+	server, err := NewServer(id, opts...)
 	if err != nil {
-		return model
+		return model, fmt.Errorf("failed to start canvas: %w", err)
 	}
 
 	adapter := &BubbleTeaAdapter{
-		server: server,
-		model:  model,
+		server:   server,
+		model:    model,
+		logger:   server.log,
+		loopReqs: make(chan loopRequest),
 	}
 
 	server.SetModel(adapter)
 	server.Start()
+	adapter.logger.Info("canvas started", "socket", server.SocketPath(), "addrs", server.Addrs())
 
-	return adapter
+	// Bubble Tea turns SIGINT and SIGTERM into a quit, but a closed
+	// terminal or killed tmux pane ends the process with SIGHUP.
+	server.HandleSignals(syscall.SIGHUP)
+
+	return adapter, nil
+}
+
+// Run wraps the model like Wrap, runs it as a Bubble Tea program and shuts
+// the canvas server down when the program ends, including by error or
+// panic. It returns the final state of the unwrapped model.
+func Run(canvasID string, model tea.Model, opts ...tea.ProgramOption) (tea.Model, error) {
+	wrapped := Wrap(canvasID, model)
+	adapter, ok := wrapped.(*BubbleTeaAdapter)
+	if !ok {
+		return tea.NewProgram(model, opts...).Run()
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		if err := adapter.server.Shutdown(ctx); err != nil {
+			adapter.logger.Warn("canvas requests cut off by shutdown", "err", err)
+		}
+	}()
+
+	final, err := tea.NewProgram(adapter, opts...).Run()
+	if a, ok := final.(*BubbleTeaAdapter); ok {
+		final = a.model
+	}
+	return final, err
 }
 
 func (a *BubbleTeaAdapter) Init() tea.Cmd {
-	return a.model.Init()
+	return tea.Batch(a.model.Init(), a.waitForLoop())
+}
+
+// waitForLoop delivers the next loop request to Update
+func (a *BubbleTeaAdapter) waitForLoop() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case req := <-a.loopReqs:
+			return req
+		case <-a.server.done:
+			return nil
+		}
+	}
 }
 
 func (a *BubbleTeaAdapter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if _, ok := msg.(tea.QuitMsg); ok {
+		a.logger.Info("canvas stopped with the program")
 		a.server.Stop()
 	}
 
+	if req, ok := msg.(loopRequest); ok {
+		m := &loopModel{BubbleTeaAdapter: a}
+		req.reply <- req.fn(m)
+		if !m.quit {
+			return a, a.waitForLoop()
+		}
+		a.logger.Info("canvas closing the program on request")
+
+		// Let the close response go out before removing the socket.
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+			defer cancel()
+			if err := a.server.Shutdown(ctx); err != nil {
+				a.logger.Warn("canvas requests cut off by shutdown", "err", err)
+			}
+		}()
+		return a, tea.Quit
+	}
+
 	newModel, cmd := a.model.Update(msg)
 	a.model = newModel
 
@@ -53,14 +191,27 @@ func (a *BubbleTeaAdapter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *BubbleTeaAdapter) View() string {
-	return a.model.View()
+	view := a.model.View()
+	if view != a.lastView {
+		a.lastView = view
+		a.server.SendEvent(MsgUpdated, nil)
+	}
+	return view
 }
 
 func (a *BubbleTeaAdapter) CanvasState() StatePayload {
-	if sp, ok := a.model.(StateProvider); ok {
-		return sp.CanvasState()
-	}
-	return StatePayload{}
+	state, _, _ := modelState(a.model)
+	return state
+}
+
+// Model returns the wrapped model, e.g. for handlers registered with
+// WithHandler, whose requests carry the adapter as Request.Model
+func (a *BubbleTeaAdapter) Model() tea.Model {
+	return a.model
+}
+
+func (a *BubbleTeaAdapter) canvasModel() any {
+	return a.model
 }
 
 func (a *BubbleTeaAdapter) CanvasView() string {
@@ -80,3 +231,57 @@ func (a *BubbleTeaAdapter) HandleCanvasInput(text string) error {
 	}
 	return nil
 }
+
+// HandleCanvasClose asks the program to quit, letting the wrapped model
+// veto through CloseHandler
+func (a *BubbleTeaAdapter) HandleCanvasClose() error {
+	result, err := a.runInLoop(func(m any) any {
+		return m.(*loopModel).HandleCanvasClose()
+	})
+	if err != nil {
+		return err
+	}
+	err, _ = result.(error)
+	return err
+}
+
+// runInLoop calls fn with the model from inside the program's event loop,
+// so that nothing updates the model while fn runs, and returns fn's result
+func (a *BubbleTeaAdapter) runInLoop(fn func(model any) any) (any, error) {
+	req := loopRequest{
+		fn:    func(m *loopModel) any { return fn(m) },
+		reply: make(chan any, 1),
+	}
+	select {
+	case a.loopReqs <- req:
+	case <-time.After(closeTimeout):
+		a.logger.Warn("canvas request not picked up by the program", "timeout", closeTimeout)
+		return nil, errors.New("app is not processing events")
+	}
+
+	select {
+	case result := <-req.reply:
+		return result, nil
+	case <-time.After(closeTimeout):
+		a.logger.Warn("canvas request not answered by the program", "timeout", closeTimeout)
+		return nil, errors.New("app did not answer the request")
+	}
+}
+
+// HandleCanvasClose consults the model's CloseHandler and, unless it
+// vetoes, makes Update quit the program once the loop request is done
+func (m *loopModel) HandleCanvasClose() error {
+	if ch, ok := m.model.(CloseHandler); ok {
+		if err := ch.HandleCanvasClose(); err != nil {
+			m.logger.Info("canvas close vetoed by the app", "reason", err)
+			return err
+		}
+	}
+	m.quit = true
+	return nil
+}
+
+// runInLoop calls fn directly, being in the loop already
+func (m *loopModel) runInLoop(fn func(model any) any) (any, error) {
+	return fn(m), nil
+}
@@ -0,0 +1,85 @@
+package canvas
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// teaModel is a Bubble Tea model answering close requests with err
+type teaModel struct{ err error }
+
+func (m teaModel) Init() tea.Cmd                       { return nil }
+func (m teaModel) Update(tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+func (m teaModel) View() string                        { return "" }
+func (m teaModel) HandleCanvasClose() error            { return m.err }
+
+// runLoop hands the next loop request to the adapter's Update, as the program
+// would, and returns the command Update produced
+func runLoop(a *BubbleTeaAdapter) <-chan tea.Cmd {
+	cmds := make(chan tea.Cmd, 1)
+	go func() {
+		req := <-a.loopReqs
+		_, cmd := a.Update(req)
+		cmds <- cmd
+	}()
+	return cmds
+}
+
+func TestAdapterClose(t *testing.T) {
+	tempSocketDir(t)
+	s := startServer(t, "adapter", nil)
+	a := &BubbleTeaAdapter{server: s, model: teaModel{}, logger: discardLogger(), loopReqs: make(chan loopRequest)}
+	cmds := runLoop(a)
+	if err := a.HandleCanvasClose(); err != nil {
+		t.Fatalf("accepted close returned %v", err)
+	}
+	if cmd := <-cmds; cmd == nil || cmd() != tea.Quit() {
+		t.Error("accepted close does not quit the program")
+	}
+
+	// The server shuts down once the close response is out.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(s.SocketPath()); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("socket still there after an accepted close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAdapterCloseVeto(t *testing.T) {
+	a := &BubbleTeaAdapter{model: teaModel{errors.New("unsaved changes")}, logger: discardLogger(), loopReqs: make(chan loopRequest)}
+	cmds := runLoop(a)
+	if err := a.HandleCanvasClose(); err == nil || err.Error() != "unsaved changes" {
+		t.Fatalf("got %v, want the model's reason", err)
+	}
+	// The command waits for the next close request rather than quitting.
+	if cmd := <-cmds; cmd == nil {
+		t.Error("vetoed close stops waiting for close requests")
+	}
+}
+
+func TestAdapterCloseTimeout(t *testing.T) {
+	defer func(d time.Duration) { closeTimeout = d }(closeTimeout)
+	closeTimeout = 20 * time.Millisecond
+
+	// Nothing reads the request.
+	a := &BubbleTeaAdapter{model: teaModel{}, logger: discardLogger(), loopReqs: make(chan loopRequest)}
+	if err := a.HandleCanvasClose(); err == nil || !strings.Contains(err.Error(), "not processing events") {
+		t.Errorf("got %v for a stuck program", err)
+	}
+
+	// The request is taken but never answered.
+	go func() { <-a.loopReqs }()
+	if err := a.HandleCanvasClose(); err == nil || !strings.Contains(err.Error(), "did not answer") {
+		t.Errorf("got %v for an unanswered request", err)
+	}
+}
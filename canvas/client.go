@@ -2,29 +2,151 @@ package canvas
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// ErrCloseRefused is returned by Client.Close when the app vetoes the close
+var ErrCloseRefused = errors.New("canvas refused to close")
+
 // Client connects to a canvas server to query/control it
 type Client struct {
-	id     string
-	socket string
+	id      string
+	network string // "unix", "tcp", "ws" or "ssh"
+	socket  string // socket path, or host:port for network canvases
+	path    string // WebSocket request path
+	tls     *tls.Config
+	token   string
+	sshHost string // [user@]host for ssh:// addresses
+	sshPort string
+	sshCmd  string // see WithSSHCommand
+	logger  *slog.Logger
+}
+
+// ClientOption configures a Client created by Dial
+type ClientOption func(*Client)
+
+// WithClientToken sets the token sent to canvases that require one,
+// overriding a token in the address and CANVAS_TOKEN
+func WithClientToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithClientLogger makes the client log its requests to logger. By default
+// it logs to the file named by CANVAS_LOG, if any.
+func WithClientLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithClientTLS sets the TLS configuration for tls:// and wss:// addresses
+func WithClientTLS(cfg *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tls = cfg
+	}
 }
 
 // NewClient creates a client for the given canvas ID
 func NewClient(id string) *Client {
 	return &Client{
-		id:     id,
-		socket: SocketPath(id),
+		id:      id,
+		network: "unix",
+		socket:  SocketPath(id),
+		logger:  defaultLogger(),
 	}
 }
 
 // NewClientWithSocket creates a client with a custom socket path
 func NewClientWithSocket(socket string) *Client {
-	return &Client{socket: socket}
+	return &Client{network: "unix", socket: socket, logger: defaultLogger()}
+}
+
+// Dial creates a client for a canvas ID, a socket path or a URL:
+//
+//	my-app
+//	/tmp/opencode-canvas/my-app.sock
+//	unix:///tmp/opencode-canvas/my-app.sock
+//	tcp://host:port/my-app, tls://host:port/my-app
+//	ws://host:port/my-app, wss://host:port/my-app
+//	ssh://user@host/my-app
+//
+// ssh:// reaches a canvas in RemoteSocketDir on another machine through the
+// local ssh binary's stdio forwarding. A token can be given as
+// "?token=..." or in CANVAS_TOKEN. No connection is made until the first
+// request.
+func Dial(addr string, opts ...ClientOption) (*Client, error) {
+	if !strings.Contains(addr, "://") {
+		c := NewClient(addr)
+		if strings.ContainsRune(addr, filepath.Separator) || strings.HasSuffix(addr, ".sock") {
+			c = NewClientWithSocket(addr)
+		}
+		for _, opt := range opts {
+			opt(c)
+		}
+		return c, nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canvas address '%s': %w", addr, err)
+	}
+
+	c := &Client{
+		id:     strings.Trim(u.Path, "/"),
+		token:  u.Query().Get("token"),
+		logger: defaultLogger(),
+	}
+	switch u.Scheme {
+	case "unix":
+		c.network, c.socket = "unix", u.Path
+		c.id = strings.TrimSuffix(filepath.Base(u.Path), ".sock")
+	case "tcp", "tls":
+		c.network, c.socket = "tcp", u.Host
+	case "ws", "wss":
+		c.network, c.socket, c.path = "ws", u.Host, "/"+c.id
+	case "ssh":
+		c.network, c.socket = "ssh", remoteSocketPath(c.id)
+		c.id = strings.TrimSuffix(path.Base(c.id), ".sock")
+		c.sshHost, c.sshPort = u.Hostname(), u.Port()
+		if u.User != nil {
+			c.sshHost = u.User.Username() + "@" + c.sshHost
+		}
+	default:
+		return nil, fmt.Errorf("unsupported canvas address scheme '%s'", u.Scheme)
+	}
+	if c.network != "unix" && u.Host == "" {
+		return nil, fmt.Errorf("invalid canvas address '%s': missing host:port", addr)
+	}
+	if u.Scheme == "tls" || u.Scheme == "wss" {
+		c.tls = &tls.Config{}
+	}
+	if c.token == "" {
+		c.token = os.Getenv("CANVAS_TOKEN")
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tls != nil && c.tls.ServerName == "" {
+		c.tls = c.tls.Clone()
+		c.tls.ServerName = u.Hostname()
+	}
+	return c, nil
 }
 
 // GetState queries the canvas for its current state
@@ -35,9 +157,7 @@ func (c *Client) GetState() (*StatePayload, error) {
 	}
 
 	if resp.Type == MsgError {
-		var errPayload ErrorPayload
-		resp.ParsePayload(&errPayload)
-		return nil, fmt.Errorf("%s: %s", errPayload.Code, errPayload.Message)
+		return nil, responseError(resp)
 	}
 
 	var state StatePayload
@@ -48,6 +168,26 @@ func (c *Client) GetState() (*StatePayload, error) {
 	return &state, nil
 }
 
+// GetStateSchema queries the canvas for the JSON Schema of its custom
+// state, as published by TypedStateProvider models
+func (c *Client) GetStateSchema() (json.RawMessage, error) {
+	resp, err := c.send(MsgGetStateSchema, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type == MsgError {
+		return nil, responseError(resp)
+	}
+
+	var payload StateSchemaPayload
+	if err := resp.ParsePayload(&payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Schema, nil
+}
+
 // GetView queries the canvas for its rendered view
 func (c *Client) GetView() (string, error) {
 	resp, err := c.send(MsgGetView, nil)
@@ -56,9 +196,7 @@ func (c *Client) GetView() (string, error) {
 	}
 
 	if resp.Type == MsgError {
-		var errPayload ErrorPayload
-		resp.ParsePayload(&errPayload)
-		return "", fmt.Errorf("%s: %s", errPayload.Code, errPayload.Message)
+		return "", responseError(resp)
 	}
 
 	var view ViewPayload
@@ -69,6 +207,60 @@ func (c *Client) GetView() (string, error) {
 	return view.Content, nil
 }
 
+// StreamView writes the rendered view to w as the canvas sends it, in
+// chunks of at most chunkSize bytes, so that a very large view never
+// travels as a single message
+func (c *Client) StreamView(w io.Writer, chunkSize int) error {
+	conn, reader, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	msg, err := NewMessage(MsgGetView, ViewRequestPayload{ChunkSize: chunkSize})
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(conn).Encode(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", connError(err))
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", connError(err))
+		}
+		// Each chunk gets the time a whole response would.
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		var resp Message
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		switch resp.Type {
+		case MsgViewChunk:
+			var chunk ViewChunkPayload
+			if err := resp.ParsePayload(&chunk); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, chunk.Content); err != nil {
+				return err
+			}
+		case MsgView:
+			var view ViewPayload
+			if err := resp.ParsePayload(&view); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, view.Content)
+			return err
+		case MsgError:
+			return responseError(&resp)
+		default:
+			return fmt.Errorf("unexpected response type: %s", resp.Type)
+		}
+	}
+}
+
 // SendKey sends a key press to the canvas
 func (c *Client) SendKey(key string) error {
 	resp, err := c.send(MsgSendKey, KeyPayload{Key: key})
@@ -77,9 +269,7 @@ func (c *Client) SendKey(key string) error {
 	}
 
 	if resp.Type == MsgError {
-		var errPayload ErrorPayload
-		resp.ParsePayload(&errPayload)
-		return fmt.Errorf("%s: %s", errPayload.Code, errPayload.Message)
+		return responseError(resp)
 	}
 
 	return nil
@@ -93,18 +283,193 @@ func (c *Client) SendInput(text string) error {
 	}
 
 	if resp.Type == MsgError {
-		var errPayload ErrorPayload
-		resp.ParsePayload(&errPayload)
-		return fmt.Errorf("%s: %s", errPayload.Code, errPayload.Message)
+		return responseError(resp)
 	}
 
 	return nil
 }
 
-// Close requests the canvas to close
+// Close requests the canvas to close. If the app refuses, the error
+// matches ErrCloseRefused and carries the reason.
 func (c *Client) Close() error {
-	_, err := c.send(MsgClose, nil)
-	return err
+	result, err := c.RequestClose()
+	if err != nil {
+		return err
+	}
+	if !result.Exited {
+		reason := result.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return fmt.Errorf("%w: %s", ErrCloseRefused, reason)
+	}
+	return nil
+}
+
+// RequestClose requests the canvas to close and reports whether the app
+// is exiting or why it refused
+func (c *Client) RequestClose() (*ClosePayload, error) {
+	resp, err := c.send(MsgClose, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type == MsgError {
+		return nil, responseError(resp)
+	}
+
+	// A bare ack comes from servers with nothing to veto the close.
+	result := ClosePayload{Exited: true}
+	if resp.Payload != nil {
+		result = ClosePayload{}
+		if err := resp.ParsePayload(&result); err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+// Batch sends several requests in one round trip. The canvas runs them in
+// order with no other canvas request in between and returns one response
+// per request; failed requests get an error response in their slot. A
+// program wrapped with Wrap runs the whole batch between two updates, so
+// the requests see one version of its model; other apps may change their
+// model while a batch runs.
+func (c *Client) Batch(msgs ...*Message) ([]*Message, error) {
+	batch := BatchPayload{Messages: make([]Message, 0, len(msgs))}
+	for _, msg := range msgs {
+		batch.Messages = append(batch.Messages, *msg)
+	}
+	resp, err := c.send(MsgBatch, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type == MsgError {
+		return nil, responseError(resp)
+	}
+
+	var result BatchResultPayload
+	if err := resp.ParsePayload(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Responses) != len(msgs) {
+		return nil, fmt.Errorf("batch of %d requests got %d responses", len(msgs), len(result.Responses))
+	}
+
+	responses := make([]*Message, len(result.Responses))
+	for i := range result.Responses {
+		responses[i] = &result.Responses[i]
+	}
+	return responses, nil
+}
+
+// Snapshot fetches the state and view of the canvas in a single batch, so
+// both describe the same moment for programs wrapped with Wrap (see
+// Batch). A part the canvas cannot provide is left out and explained in
+// Snapshot.Error.
+func (c *Client) Snapshot() (*Snapshot, error) {
+	return c.snapshot(true, true)
+}
+
+func (c *Client) snapshot(state, view bool) (*Snapshot, error) {
+	var msgs []*Message
+	if state {
+		msg, _ := NewMessage(MsgGetState, nil)
+		msgs = append(msgs, msg)
+	}
+	if view {
+		msg, _ := NewMessage(MsgGetView, nil)
+		msgs = append(msgs, msg)
+	}
+	responses, err := c.Batch(msgs...)
+	var protoErr *ProtocolError
+	if errors.As(err, &protoErr) && protoErr.Code == CodeUnknownType {
+		// The canvas predates batches; ask one request at a time.
+		responses, err = c.sendEach(msgs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{}
+	var errs []error
+	for _, resp := range responses {
+		switch resp.Type {
+		case MsgState:
+			snap.State = &StatePayload{}
+			if err := resp.ParsePayload(snap.State); err != nil {
+				errs = append(errs, err)
+			}
+		case MsgView:
+			var payload ViewPayload
+			if err := resp.ParsePayload(&payload); err != nil {
+				errs = append(errs, err)
+			} else {
+				snap.View = &payload.Content
+			}
+		case MsgError:
+			errs = append(errs, responseError(resp))
+		}
+	}
+	snap.setErr(errs...)
+	return snap, nil
+}
+
+// sendEach sends requests one by one and returns their responses
+func (c *Client) sendEach(msgs []*Message) ([]*Message, error) {
+	responses := make([]*Message, 0, len(msgs))
+	for _, msg := range msgs {
+		var payload any
+		if msg.Payload != nil {
+			payload = msg.Payload
+		}
+		resp, err := c.send(msg.Type, payload)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// Stats fetches the counters and latencies the canvas has collected
+func (c *Client) Stats() (*StatsPayload, error) {
+	resp, err := c.send(MsgStats, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type == MsgError {
+		return nil, responseError(resp)
+	}
+
+	var stats StatsPayload
+	if err := resp.ParsePayload(&stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// Call sends a request of any type, such as one registered with
+// Server.Handle, and decodes the response payload into result unless it is
+// nil. Error responses are returned as *ProtocolError.
+func (c *Client) Call(msgType MessageType, payload, result any) error {
+	resp, err := c.send(msgType, payload)
+	if err != nil {
+		return err
+	}
+
+	if resp.Type == MsgError {
+		return responseError(resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return resp.ParsePayload(result)
 }
 
 // Ping checks if the canvas is responsive
@@ -113,15 +478,175 @@ func (c *Client) Ping() bool {
 	return err == nil
 }
 
-func (c *Client) send(msgType MessageType, payload any) (*Message, error) {
-	conn, err := net.DialTimeout("unix", c.socket, 5*time.Second)
+// Subscribe opens a dedicated connection that receives the canvas's async
+// events (MsgUpdated, MsgSelected, ...). The returned channel is closed
+// when ctx is cancelled or the canvas goes away.
+func (c *Client) Subscribe(ctx context.Context) (<-chan *Message, error) {
+	conn, reader, err := c.dial()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to canvas: %w", err)
+		return nil, err
 	}
-	defer conn.Close()
 
+	msg, _ := NewMessage(MsgSubscribe, nil)
+	if err := json.NewEncoder(conn).Encode(msg); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send message: %w", connError(err))
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read response: %w", connError(err))
+	}
+
+	var resp Message
+	if err := json.Unmarshal(line, &resp); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if resp.Type == MsgError {
+		conn.Close()
+		return nil, responseError(&resp)
+	}
+	conn.SetDeadline(time.Time{})
+
+	events := make(chan *Message)
+	done := make(chan struct{})
+	go func() {
+		// Unblock the read below on cancellation.
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	go func() {
+		defer close(events)
+		defer close(done)
+		defer conn.Close()
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				c.logger.Debug("canvas subscription ended", "canvas", c.id, "err", err)
+				return
+			}
+			var event Message
+			if err := json.Unmarshal(line, &event); err != nil {
+				continue
+			}
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// dial connects to the canvas and authenticates if needed. The connection
+// has a deadline for the request; the reader must be used for responses.
+func (c *Client) dial() (net.Conn, *bufio.Reader, error) {
+	var conn net.Conn
+	var err error
+	switch c.network {
+	case "unix":
+		conn, err = net.DialTimeout("unix", c.socket, 5*time.Second)
+	case "ssh":
+		conn, err = c.dialSSH()
+	default:
+		conn, err = net.DialTimeout("tcp", c.socket, 5*time.Second)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to canvas: %w", connError(err))
+	}
 	conn.SetDeadline(time.Now().Add(10 * time.Second))
 
+	if c.tls != nil {
+		tlsConn := tls.Client(conn, c.tls)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to connect to canvas: %w", connError(err))
+		}
+		conn = tlsConn
+	}
+	if c.network == "ws" {
+		wsConn, err := clientWebSocket(conn, c.socket, c.path)
+		if err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to connect to canvas: %w", connError(err))
+		}
+		conn = wsConn
+	}
+
+	reader := bufio.NewReader(conn)
+	if c.token != "" && (c.network == "tcp" || c.network == "ws") {
+		if err := c.authenticate(conn, reader); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, reader, nil
+}
+
+// authenticate sends the token and waits for it to be accepted
+func (c *Client) authenticate(conn net.Conn, reader *bufio.Reader) error {
+	msg, _ := NewMessage(MsgAuth, AuthPayload{Token: c.token})
+	if err := json.NewEncoder(conn).Encode(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", connError(err))
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", connError(err))
+	}
+
+	var resp Message
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if resp.Type == MsgError {
+		return responseError(&resp)
+	}
+	return nil
+}
+
+// DialConn opens a connection to the canvas for speaking the protocol
+// directly: authenticated if the client has a token, and without
+// deadlines
+func (c *Client) DialConn() (net.Conn, error) {
+	conn, reader, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, r: reader}, nil
+}
+
+// bufferedConn reads a connection through the reader that may already
+// hold the start of its input
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *Client) send(msgType MessageType, payload any) (resp *Message, err error) {
+	start := time.Now()
+	defer func() {
+		c.logRequest(msgType, resp, err, time.Since(start))
+	}()
+
+	conn, reader, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
 	// Send request
 	msg, err := NewMessage(msgType, payload)
 	if err != nil {
@@ -130,20 +655,33 @@ func (c *Client) send(msgType MessageType, payload any) (*Message, error) {
 
 	encoder := json.NewEncoder(conn)
 	if err := encoder.Encode(msg); err != nil {
-		return nil, fmt.Errorf("failed to send message: %w", err)
+		return nil, fmt.Errorf("failed to send message: %w", connError(err))
 	}
 
 	// Read response
-	reader := bufio.NewReader(conn)
 	line, err := reader.ReadBytes('\n')
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", connError(err))
 	}
 
-	var resp Message
-	if err := json.Unmarshal(line, &resp); err != nil {
+	resp = &Message{}
+	if err := json.Unmarshal(line, resp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return &resp, nil
+	return resp, nil
+}
+
+// logRequest records a request; failures are the caller's to report, so
+// they are logged at debug level like everything else
+func (c *Client) logRequest(msgType MessageType, resp *Message, err error, elapsed time.Duration) {
+	attrs := []any{"canvas", c.id, "type", msgType, "duration", elapsed}
+	switch {
+	case err != nil:
+		c.logger.Debug("canvas request failed", append(attrs, "err", err)...)
+	case resp.Type == MsgError:
+		c.logger.Debug("canvas request failed", append(attrs, "err", responseError(resp))...)
+	default:
+		c.logger.Debug("canvas request done", attrs...)
+	}
 }
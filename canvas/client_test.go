@@ -0,0 +1,56 @@
+package canvas
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitGoroutines waits for the number of goroutines to drop to n
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines left, want %d:\n%s", runtime.NumGoroutine(), n, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	for _, end := range []string{"cancel", "server stops"} {
+		t.Run(end, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			s := newTestServer(t, viewModel("x"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c, _ := Dial(s.SocketPath())
+			events, err := c.Subscribe(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s.SendEvent(MsgUpdated, nil)
+			select {
+			case event := <-events:
+				if event.Type != MsgUpdated {
+					t.Errorf("got %q, want %q", event.Type, MsgUpdated)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no event")
+			}
+
+			if end == "cancel" {
+				cancel()
+			}
+			s.Stop()
+			for range events {
+			}
+			waitGoroutines(t, before)
+		})
+	}
+}
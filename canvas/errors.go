@@ -0,0 +1,73 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Error codes sent in ErrorPayload.Code
+const (
+	CodeParseError         = "parse_error"
+	CodeInvalidPayload     = "invalid_payload"
+	CodeUnknownType        = "unknown_type"
+	CodeNotSupported       = "not_supported"
+	CodeStateError         = "state_error"
+	CodeKeyError           = "key_error"
+	CodeInputError         = "input_error"
+	CodeUnauthorized       = "unauthorized"
+	CodeTooManyConnections = "too_many_connections"
+	CodeMessageTooLarge    = "message_too_large"
+	CodeInternalError      = "internal_error"
+)
+
+// Errors returned by Client, to be checked with errors.Is
+var (
+	// ErrCanvasNotFound means nothing is listening at the canvas's address
+	ErrCanvasNotFound = errors.New("canvas not found")
+
+	// ErrNotSupported means the canvas's model lacks the interface a
+	// request needs, e.g. KeyHandler for SendKey
+	ErrNotSupported = errors.New("not supported by the canvas")
+
+	// ErrTimeout means the canvas did not answer in time
+	ErrTimeout = errors.New("canvas did not respond in time")
+)
+
+// ProtocolError is an error response from a canvas. Use errors.As to get
+// its Code; not_supported errors also match ErrNotSupported.
+type ProtocolError struct {
+	Code    string
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is makes not_supported errors match ErrNotSupported
+func (e *ProtocolError) Is(target error) bool {
+	return target == ErrNotSupported && e.Code == CodeNotSupported
+}
+
+// responseError converts an error response into a *ProtocolError
+func responseError(resp *Message) error {
+	var payload ErrorPayload
+	resp.ParsePayload(&payload)
+	return &ProtocolError{Code: payload.Code, Message: payload.Message}
+}
+
+// connError tags a connection error with ErrTimeout or ErrCanvasNotFound
+// when it is one
+func connError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w: %w", ErrCanvasNotFound, err)
+	}
+	return err
+}
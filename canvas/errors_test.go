@@ -0,0 +1,75 @@
+package canvas
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestProtocolErrorIs(t *testing.T) {
+	tests := []struct {
+		code   string
+		target error
+		want   bool
+	}{
+		{CodeNotSupported, ErrNotSupported, true},
+		{CodeKeyError, ErrNotSupported, false},
+		{CodeNotSupported, ErrCanvasNotFound, false},
+		{CodeNotSupported, ErrTimeout, false},
+	}
+	for _, tt := range tests {
+		err := error(&ProtocolError{Code: tt.code, Message: "m"})
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", err, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestConnError(t *testing.T) {
+	dialErr := func(errno syscall.Errno) error {
+		return &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", errno)}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want error // nil if the error stays untagged
+	}{
+		{"timeout", &net.OpError{Op: "read", Net: "unix", Err: os.ErrDeadlineExceeded}, ErrTimeout},
+		{"missing socket", dialErr(syscall.ENOENT), ErrCanvasNotFound},
+		{"refused", dialErr(syscall.ECONNREFUSED), ErrCanvasNotFound},
+		{"permission denied", dialErr(syscall.EACCES), nil},
+		{"other", errors.New("broken"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := connError(tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v does not wrap the original error", err)
+			}
+			for _, sentinel := range []error{ErrTimeout, ErrCanvasNotFound} {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "state-only", stateModel("x"))
+
+	_, err := NewClientWithSocket(filepath.Join(t.TempDir(), "missing.sock")).GetState()
+	if !errors.Is(err, ErrCanvasNotFound) {
+		t.Errorf("got %v for a missing socket, want ErrCanvasNotFound", err)
+	}
+
+	_, err = NewClient("state-only").GetView()
+	var protoErr *ProtocolError
+	if !errors.Is(err, ErrNotSupported) || !errors.As(err, &protoErr) || protoErr.Code != CodeNotSupported {
+		t.Errorf("got %v for an unsupported request, want a not_supported ProtocolError", err)
+	}
+}
@@ -0,0 +1,66 @@
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultChunkSize is the chunk size StreamView asks for
+const DefaultChunkSize = 64 << 10
+
+// errMessageTooLarge is returned when a request exceeds the size limit
+var errMessageTooLarge = errors.New("message too large")
+
+// readMessage reads one newline-terminated message, failing with
+// errMessageTooLarge once it exceeds limit bytes without the newline.
+// A limit of zero means no limit.
+func readMessage(r *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	for {
+		frag, err := r.ReadSlice('\n')
+		if limit > 0 && len(line)+len(bytes.TrimSuffix(frag, []byte("\n"))) > limit {
+			return nil, errMessageTooLarge
+		}
+		line = append(line, frag...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// writeResponse sends the response to req, splitting a view into chunks
+// if req asked for them
+func (s *Server) writeResponse(conn net.Conn, enc *json.Encoder, req, resp *Message) error {
+	var opts ViewRequestPayload
+	if req.Type != MsgGetView || resp.Type != MsgView || req.ParsePayload(&opts) != nil || opts.ChunkSize == 0 {
+		return s.send(enc, resp)
+	}
+
+	var view ViewPayload
+	resp.ParsePayload(&view)
+	for len(view.Content) > opts.ChunkSize {
+		n := opts.ChunkSize
+		// Keep runes whole so every chunk is valid UTF-8.
+		for n > opts.ChunkSize-utf8.UTFMax && !utf8.RuneStart(view.Content[n]) {
+			n--
+		}
+		chunk, _ := NewMessage(MsgViewChunk, ViewChunkPayload{Content: view.Content[:n]})
+		if err := s.send(enc, chunk); err != nil {
+			return err
+		}
+		view.Content = view.Content[n:]
+		view.Chunked = true
+
+		// The write timeout applies to each chunk, not the whole view.
+		if s.opts.writeTimeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(s.opts.writeTimeout))
+		}
+	}
+	last, _ := NewMessage(MsgView, view)
+	return s.send(enc, last)
+}
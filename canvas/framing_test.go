@@ -0,0 +1,135 @@
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// viewModel serves a fixed view
+type viewModel string
+
+func (m viewModel) CanvasView() string { return string(m) }
+
+// newTestServer starts a server for model in a temporary socket directory
+func newTestServer(t *testing.T, model any, opts ...ServerOption) *Server {
+	t.Helper()
+	s, err := NewServer("test", append([]ServerOption{WithSocketDir(t.TempDir())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetModel(model)
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestReadMessage(t *testing.T) {
+	long := strings.Repeat("x", 5000)
+
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  []string // messages read before err
+		err   error
+	}{
+		{name: "one line", in: "abc\n", want: []string{"abc\n"}, err: io.EOF},
+		{name: "several lines", in: "a\nb\n", want: []string{"a\n", "b\n"}, err: io.EOF},
+		{name: "no trailing newline", in: "abc", want: []string{"abc"}, err: io.EOF},
+		{name: "longer than the buffer", in: long + "\n", want: []string{long + "\n"}, err: io.EOF},
+		{name: "at the limit", in: "abcd\n", limit: 4, want: []string{"abcd\n"}, err: io.EOF},
+		{name: "over the limit", in: "abcde\n", limit: 4, err: errMessageTooLarge},
+		{name: "over the limit across buffers", in: long + "\n", limit: 4500, err: errMessageTooLarge},
+		{name: "limit per message", in: "abc\nabcde\n", limit: 4, want: []string{"abc\n"}, err: errMessageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReaderSize(strings.NewReader(tt.in), 16)
+			var got []string
+			var err error
+			for {
+				var line []byte
+				line, err = readMessage(r, tt.limit)
+				if len(line) > 0 {
+					got = append(got, string(line))
+				}
+				if err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkedView(t *testing.T) {
+	// Multi-byte runes straddle the chunk boundaries.
+	view := strings.Repeat("é世🎨x", 1000)
+	s := newTestServer(t, viewModel(view))
+
+	conn, err := net.Dial("unix", s.SocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	req, _ := NewMessage(MsgGetView, ViewRequestPayload{ChunkSize: MinChunkSize})
+	json.NewEncoder(conn).Encode(req)
+
+	dec := json.NewDecoder(conn)
+	var got strings.Builder
+	chunks := 0
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		var part ViewPayload
+		msg.ParsePayload(&part)
+		if len(part.Content) > MinChunkSize || !utf8.ValidString(part.Content) {
+			t.Fatalf("chunk %d: %d bytes, valid UTF-8 %v", chunks, len(part.Content), utf8.ValidString(part.Content))
+		}
+		got.WriteString(part.Content)
+		if msg.Type == MsgView {
+			if !part.Chunked {
+				t.Error("last part is not marked as chunked")
+			}
+			break
+		}
+		if msg.Type != MsgViewChunk {
+			t.Fatalf("got %q", msg.Type)
+		}
+		chunks++
+	}
+	if got.String() != view {
+		t.Error("reassembled view differs")
+	}
+	if want := len(view) / MinChunkSize; chunks < want {
+		t.Errorf("got %d chunks, want at least %d", chunks, want)
+	}
+
+	// The client reassembles the same view.
+	c, err := Dial(s.SocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := c.StreamView(&b, MinChunkSize); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != view {
+		t.Error("StreamView returned a different view")
+	}
+}
@@ -0,0 +1,136 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// paneGrace is how long a tmux pane file may exist without a socket. The
+// spawn command writes it before the TUI has started listening.
+const paneGrace = time.Minute
+
+// socketGrace is how long a socket refusing connections is left alone. A
+// starting server binds its socket a moment before it listens on it.
+const socketGrace = 5 * time.Second
+
+// PanePath returns the path of the file recording the tmux pane a canvas
+// was spawned in
+func PanePath(id string) string {
+	return filepath.Join(DefaultSocketDir(), fmt.Sprintf("%s.pane", id))
+}
+
+// gcParallel bounds how many canvases GC checks at once
+const gcParallel = 8
+
+// staleSocket reports whether a canvas socket is left over from a
+// server that is gone. Only a refused connection counts: the PID in the
+// metadata says nothing about servers in another PID namespace sharing
+// the directory, so a live server is never considered stale.
+func staleSocket(socket string) bool {
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, os.ErrNotExist)
+	}
+	conn.Close()
+	return false
+}
+
+// GC removes the sockets, metadata and tmux pane files of canvases whose
+// socket refuses connections, or that have no socket and whose server
+// process is gone. It returns the IDs it cleaned up.
+func GC() ([]string, error) {
+	return gcDir(DefaultSocketDir(), "")
+}
+
+// gcDir collects the stale canvases in a socket directory, leaving the
+// canvas skip alone
+func gcDir(dir, skip string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read socket directory: %w", err)
+	}
+
+	// Group the files of each canvas by extension.
+	files := make(map[string]map[string]os.DirEntry)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if ext != ".sock" && ext != ".json" && ext != ".pane" {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ext)
+		if id == skip {
+			continue
+		}
+		if files[id] == nil {
+			files[id] = make(map[string]os.DirEntry)
+		}
+		files[id][ext] = entry
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, gcParallel)
+		removed []string
+	)
+	for id, f := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if !gcCanvas(dir, id, f) {
+				return
+			}
+			mu.Lock()
+			removed = append(removed, id)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// gcCanvas removes the files of one canvas if it is stale and reports
+// whether it did
+func gcCanvas(dir, id string, f map[string]os.DirEntry) bool {
+	path := func(ext string) string { return filepath.Join(dir, id+ext) }
+	var stale bool
+	switch {
+	case f[".sock"] != nil:
+		info, err := f[".sock"].Info()
+		stale = err == nil && time.Since(info.ModTime()) > socketGrace && staleSocket(path(".sock"))
+	case f[".json"] != nil:
+		meta, err := readMetadataFile(path(".json"))
+		stale = err != nil || !meta.Alive()
+	default:
+		info, err := f[".pane"].Info()
+		stale = err == nil && time.Since(info.ModTime()) > paneGrace
+	}
+	if !stale {
+		return false
+	}
+
+	os.Remove(path(".sock"))
+	os.Remove(path(".json"))
+	if pane := f[".pane"]; pane != nil {
+		// A fresh pane file belongs to a canvas that is just starting.
+		if info, err := pane.Info(); err == nil && time.Since(info.ModTime()) > paneGrace {
+			os.Remove(path(".pane"))
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package canvas
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	dir := tempSocketDir(t)
+	path := func(name string) string { return filepath.Join(dir, name) }
+	touch := func(name string, age time.Duration) {
+		if err := os.WriteFile(path(name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		os.Chtimes(path(name), mtime, mtime)
+	}
+	writeMeta := func(id string, pid int) {
+		meta := &Metadata{ID: id, PID: pid, Socket: path(id + ".sock")}
+		if err := meta.write(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// listen leaves a socket behind, listening or not; closed ones are
+	// past socketGrace
+	listen := func(id string, keep bool) {
+		l, err := net.Listen("unix", path(id+".sock"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keep {
+			t.Cleanup(func() { l.Close() })
+			return
+		}
+		l.(*net.UnixListener).SetUnlinkOnClose(false)
+		l.Close()
+		mtime := time.Now().Add(-2 * socketGrace)
+		os.Chtimes(path(id+".sock"), mtime, mtime)
+	}
+	dead := deadPID(t)
+
+	// Sockets refusing connections go, whatever the metadata says.
+	listen("refused", false)
+	listen("refused-alive-pid", false)
+	writeMeta("refused-alive-pid", os.Getpid())
+	touch("refused-alive-pid.pane", 2*paneGrace)
+
+	// Fresh ones stay, as their server may not be listening yet.
+	listen("refused-fresh", false)
+	os.Chtimes(path("refused-fresh.sock"), time.Now(), time.Now())
+
+	// Listening sockets stay, even when their PID looks dead, as for a
+	// server in another PID namespace.
+	listen("listening", true)
+	listen("listening-dead-pid", true)
+	writeMeta("listening-dead-pid", dead)
+
+	// Without a socket the PID decides.
+	writeMeta("meta-dead", dead)
+	writeMeta("meta-alive", os.Getpid())
+
+	// Pane files are kept while the canvas may still be starting.
+	touch("pane-old.pane", 2*paneGrace)
+	touch("pane-fresh.pane", 0)
+	listen("refused-fresh-pane", false)
+	touch("refused-fresh-pane.pane", 0)
+
+	// The canvas being started is left alone.
+	listen("self", false)
+
+	touch("unrelated.txt", 2*paneGrace)
+
+	removed, err := gcDir(dir, "self")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"meta-dead", "pane-old", "refused", "refused-alive-pid", "refused-fresh-pane"}
+	if !slices.Equal(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+
+	var left []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	wantLeft := []string{
+		"listening-dead-pid.json", "listening-dead-pid.sock", "listening.sock",
+		"meta-alive.json", "pane-fresh.pane", "refused-fresh-pane.pane",
+		"refused-fresh.sock",
+		"self.sock", "unrelated.txt",
+	}
+	if !slices.Equal(left, wantLeft) {
+		t.Errorf("left %v, want %v", left, wantLeft)
+	}
+}
+
+func TestGCMissingDir(t *testing.T) {
+	removed, err := gcDir(filepath.Join(t.TempDir(), "missing"), "")
+	if err != nil || removed != nil {
+		t.Errorf("got %v, %v for a missing directory", removed, err)
+	}
+}
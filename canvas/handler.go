@@ -0,0 +1,248 @@
+package canvas
+
+import (
+	"fmt"
+	"net"
+	"sort"
+)
+
+// Request is a message being answered by a HandlerFunc
+type Request struct {
+	Message
+
+	// Model is the model set with SetModel. In a batch to a wrapped Bubble
+	// Tea program, all requests see the same version of it.
+	Model any
+
+	// Conn is the client's connection, e.g. for middleware that checks
+	// who is asking
+	Conn net.Conn
+
+	server *Server
+}
+
+// HandlerFunc answers a request with a response message, typically built
+// with Request.Reply or Request.Fail
+type HandlerFunc func(req *Request) *Message
+
+// Middleware wraps the handling of every request, built-in or custom
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Reply builds a response of type t carrying payload
+func (r *Request) Reply(t MessageType, payload any) *Message {
+	resp, err := NewMessage(t, payload)
+	if err != nil {
+		return r.Fail(CodeInternalError, fmt.Sprintf("failed to encode %s payload: %v", t, err))
+	}
+	return resp
+}
+
+// Fail builds an error response
+func (r *Request) Fail(code, message string) *Message {
+	return errorMessage(code, message)
+}
+
+// builtinHandlers answer the message types of the protocol. Subscriptions
+// and authentication concern the connection rather than the model and are
+// handled before requests reach a handler.
+var builtinHandlers = map[MessageType]HandlerFunc{
+	MsgGetState:       handleGetState,
+	MsgGetStateSchema: handleGetStateSchema,
+	MsgGetView:        handleGetView,
+	MsgSendKey:        handleSendKey,
+	MsgSendInput:      handleSendInput,
+	MsgClose:          handleClose,
+	MsgBatch:          handleBatch,
+	MsgStats:          handleStats,
+}
+
+// Handle registers fn for messages of type t, replacing any handler for it,
+// built-in ones included. A nil fn removes the handler. Custom types are
+// advertised in the registry's capabilities; use snake_case names so that
+// JSON-RPC clients can call them as canvas.camelCase.
+func (s *Server) Handle(t MessageType, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fn == nil {
+		delete(s.handlers, t)
+	} else {
+		s.handlers[t] = fn
+	}
+	s.buildChain()
+	s.writeCapabilities()
+}
+
+// Use adds middleware around the handling of every request. The first
+// middleware added is the outermost.
+func (s *Server) Use(mw ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middleware = append(s.middleware, mw...)
+	s.buildChain()
+}
+
+// buildChain composes the middleware around dispatch, with auditing and
+// metrics outermost so that they see what clients finally get; s.mu must
+// be held
+func (s *Server) buildChain() {
+	h := s.dispatch
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	if s.audit != nil {
+		h = s.auditMiddleware(h)
+	}
+	s.chain = s.metricsMiddleware(h)
+}
+
+// dispatch validates a request and passes it to the handler for its type
+func (s *Server) dispatch(req *Request) *Message {
+	if err := validatePayload(&req.Message); err != nil {
+		return req.Fail(CodeInvalidPayload, err.Error())
+	}
+
+	s.mu.RLock()
+	h := s.handlers[req.Type]
+	s.mu.RUnlock()
+	if h == nil {
+		return req.Fail(CodeUnknownType, fmt.Sprintf("unknown message type: %s", req.Type))
+	}
+	return h(req)
+}
+
+// customTypes lists the message types of handlers that are not built in
+func customTypes(handlers map[MessageType]HandlerFunc) []string {
+	var types []string
+	for t := range handlers {
+		if _, ok := builtinHandlers[t]; !ok {
+			types = append(types, string(t))
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// writeCapabilities advertises what the model and the handlers support;
+// s.mu must be held
+func (s *Server) writeCapabilities() {
+	s.meta.Capabilities = append(capabilitiesOf(s.model), customTypes(s.handlers)...)
+	if err := s.meta.write(); err != nil {
+		s.reportError(fmt.Errorf("failed to write canvas metadata: %w", err))
+	}
+}
+
+func handleGetState(req *Request) *Message {
+	state, ok, err := modelState(req.Model)
+	if !ok {
+		return req.Fail(CodeNotSupported, "model does not implement StateProvider")
+	}
+	if err != nil {
+		return req.Fail(CodeStateError, err.Error())
+	}
+	return req.Reply(MsgState, state)
+}
+
+func handleGetStateSchema(req *Request) *Message {
+	schema := stateSchema(req.Model)
+	if schema == nil {
+		return req.Fail(CodeNotSupported, "model does not implement TypedStateProvider")
+	}
+	return req.Reply(MsgStateSchema, StateSchemaPayload{Schema: schema})
+}
+
+func handleGetView(req *Request) *Message {
+	vp, ok := req.Model.(ViewProvider)
+	if !ok {
+		return req.Fail(CodeNotSupported, "model does not implement ViewProvider")
+	}
+	return req.Reply(MsgView, ViewPayload{Content: vp.CanvasView(), ANSI: true})
+}
+
+func handleSendKey(req *Request) *Message {
+	kh, ok := req.Model.(KeyHandler)
+	if !ok {
+		return req.Fail(CodeNotSupported, "model does not implement KeyHandler")
+	}
+	var payload KeyPayload
+	req.ParsePayload(&payload) // validated by dispatch
+	if err := kh.HandleCanvasKey(payload.Key, payload.Rune); err != nil {
+		return req.Fail(CodeKeyError, err.Error())
+	}
+	return req.Reply(MsgAck, nil)
+}
+
+func handleSendInput(req *Request) *Message {
+	ih, ok := req.Model.(InputHandler)
+	if !ok {
+		return req.Fail(CodeNotSupported, "model does not implement InputHandler")
+	}
+	var payload InputPayload
+	req.ParsePayload(&payload) // validated by dispatch
+	if err := ih.HandleCanvasInput(payload.Text); err != nil {
+		return req.Fail(CodeInputError, err.Error())
+	}
+	return req.Reply(MsgAck, nil)
+}
+
+func handleClose(req *Request) *Message {
+	req.server.mu.RLock()
+	onClose := req.server.onClose
+	req.server.mu.RUnlock()
+
+	ch, ok := req.Model.(CloseHandler)
+	if !ok && onClose == nil {
+		// Nothing here decides about the app's lifetime.
+		return req.Reply(MsgAck, nil)
+	}
+
+	result := ClosePayload{Exited: true}
+	if ok {
+		if err := ch.HandleCanvasClose(); err != nil {
+			result = ClosePayload{Reason: err.Error()}
+		}
+	}
+	if result.Exited && onClose != nil {
+		onClose()
+	}
+	return req.Reply(MsgAck, result)
+}
+
+// looper is implemented by adapters whose model belongs to another
+// goroutine, such as BubbleTeaAdapter
+type looper interface {
+	// runInLoop calls fn on that goroutine, with a model that nothing else
+	// changes until fn returns, and returns fn's result
+	runInLoop(fn func(model any) any) (any, error)
+}
+
+// handleBatch runs the requests of a batch in order, each through the
+// middleware like any other request. Models owned by another goroutine,
+// like a wrapped Bubble Tea program, run the whole batch on it between
+// two updates, so handlers in such a batch must not wait on that
+// goroutine (with Program.Send, for instance).
+func handleBatch(req *Request) *Message {
+	var batch BatchPayload
+	req.ParsePayload(&batch) // validated by dispatch
+
+	req.server.mu.RLock()
+	chain := req.server.chain
+	req.server.mu.RUnlock()
+
+	run := func(model any) any {
+		result := BatchResultPayload{Responses: make([]Message, 0, len(batch.Messages))}
+		for _, msg := range batch.Messages {
+			sub := &Request{Message: msg, Model: model, Conn: req.Conn, server: req.server}
+			result.Responses = append(result.Responses, *chain(sub))
+		}
+		return result
+	}
+	l, ok := req.Model.(looper)
+	if !ok {
+		return req.Reply(MsgBatchResult, run(req.Model))
+	}
+	result, err := l.runInLoop(run)
+	if err != nil {
+		return req.Fail(CodeInternalError, err.Error())
+	}
+	return req.Reply(MsgBatchResult, result)
+}
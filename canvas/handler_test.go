@@ -0,0 +1,121 @@
+package canvas
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// trace records the order in which middleware runs
+type trace struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (tr *trace) add(step string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.steps = append(tr.steps, step)
+}
+
+// take returns the steps so far and starts over
+func (tr *trace) take() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	steps := tr.steps
+	tr.steps = nil
+	return steps
+}
+
+// middleware records entering and leaving as name+ and name-
+func (tr *trace) middleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) *Message {
+			tr.add(name + "+")
+			defer tr.add(name + "-")
+			return next(req)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	tr := &trace{}
+	s := newTestServer(t, stateModel("x"), WithMiddleware(tr.middleware("option")))
+	s.Use(tr.middleware("a"), tr.middleware("b"))
+	s.Use(tr.middleware("c"))
+
+	if _, err := NewClientWithSocket(s.SocketPath()).GetState(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"option+", "a+", "b+", "c+", "c-", "b-", "a-", "option-"}
+	if got := tr.take(); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The requests of a batch pass through the middleware too.
+	getState, _ := NewMessage(MsgGetState, nil)
+	if _, err := NewClientWithSocket(s.SocketPath()).Batch(getState); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.take(); strings.Count(strings.Join(got, " "), "c+") != 2 {
+		t.Errorf("got %v for a batch, want the batch and its request wrapped", got)
+	}
+}
+
+func TestHandleOverride(t *testing.T) {
+	s := newTestServer(t, stateModel("builtin"), WithHandler("echo", func(req *Request) *Message {
+		var payload map[string]any
+		req.ParsePayload(&payload)
+		return req.Reply("echoed", payload)
+	}))
+	c := NewClientWithSocket(s.SocketPath())
+
+	var echoed map[string]any
+	if err := c.Call("echo", map[string]any{"n": 1}, &echoed); err != nil || echoed["n"] != float64(1) {
+		t.Errorf("custom handler returned %v, %v", echoed, err)
+	}
+	if !slices.Contains(s.Metadata().Capabilities, "echo") {
+		t.Errorf("capabilities %v do not advertise echo", s.Metadata().Capabilities)
+	}
+
+	// Built-in handlers can be replaced and removed.
+	s.Handle(MsgGetState, func(req *Request) *Message {
+		return req.Reply(MsgState, StatePayload{Mode: "custom"})
+	})
+	if state, err := c.GetState(); err != nil || state.Mode != "custom" {
+		t.Errorf("got %+v, %v from the replaced handler", state, err)
+	}
+	s.Handle(MsgGetState, nil)
+	var protoErr *ProtocolError
+	if _, err := c.GetState(); !errors.As(err, &protoErr) || protoErr.Code != CodeUnknownType {
+		t.Errorf("got %v after removing the handler, want unknown_type", err)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var reached atomic.Bool
+	s := newTestServer(t, nil, WithHandler("secret", func(req *Request) *Message {
+		reached.Store(true)
+		return req.Reply(MsgAck, nil)
+	}))
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) *Message {
+			if req.Type == "secret" {
+				return req.Fail(CodeUnauthorized, "not for you")
+			}
+			return next(req)
+		}
+	})
+
+	c := NewClientWithSocket(s.SocketPath())
+	var protoErr *ProtocolError
+	if err := c.Call("secret", nil, nil); !errors.As(err, &protoErr) || protoErr.Code != CodeUnauthorized {
+		t.Errorf("got %v, want the middleware's unauthorized", err)
+	}
+	if reached.Load() {
+		t.Error("the handler ran although the middleware answered")
+	}
+}
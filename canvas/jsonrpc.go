@@ -0,0 +1,273 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+)
+
+// Clients may speak JSON-RPC 2.0 instead of the native envelope. Requests
+// are told apart by their "jsonrpc" or "method" member, or by being a batch
+// array, and get JSON-RPC responses. Methods are the message types in camel
+// case under "canvas.", e.g. canvas.getState for get_state, with the
+// payload as params.
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000 // canvas errors; data.code has the canvas code
+)
+
+// rpcPrefix namespaces the JSON-RPC methods
+const rpcPrefix = "canvas."
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    rpcErrorData `json:"data"`
+}
+
+type rpcErrorData struct {
+	Code string `json:"code"` // canvas error code, see ErrorPayload
+}
+
+type rpcNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcNull is the id of responses to unidentifiable requests and the result
+// of requests answered by a bare ack
+var rpcNull = json.RawMessage("null")
+
+// isJSONRPC reports whether a request line is JSON-RPC rather than native
+func isJSONRPC(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if len(line) > 0 && line[0] == '[' {
+		return true
+	}
+	var probe struct {
+		JSONRPC *string `json:"jsonrpc"`
+		Method  *string `json:"method"`
+	}
+	return json.Unmarshal(line, &probe) == nil && (probe.JSONRPC != nil || probe.Method != nil)
+}
+
+// rpcMethod returns the JSON-RPC method of a message type
+func rpcMethod(t MessageType) string {
+	parts := strings.Split(string(t), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return rpcPrefix + strings.Join(parts, "")
+}
+
+// rpcMessageType returns the message type of a JSON-RPC method, or "" if
+// the method is outside the canvas namespace
+func rpcMessageType(method string) MessageType {
+	name, ok := strings.CutPrefix(method, rpcPrefix)
+	if !ok || name == "" {
+		return ""
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if i == 0 {
+			// canvas.GetState names get_state too.
+			r = unicode.ToLower(r)
+		} else if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return MessageType(b.String())
+}
+
+// parseRPC turns a JSON-RPC request into a native message, or returns the
+// error response to send instead
+func parseRPC(raw json.RawMessage) (*rpcRequest, *Message, *rpcResponse) {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		id := rpcNull
+		if req.ID != nil {
+			id = req.ID
+		}
+		return nil, nil, rpcFailure(id, rpcInvalidRequest, CodeInvalidPayload, "invalid JSON-RPC 2.0 request")
+	}
+	t := rpcMessageType(req.Method)
+	if t == "" {
+		return nil, nil, rpcFailure(req.rpcID(), rpcMethodNotFound, CodeUnknownType, "method not found: "+req.Method)
+	}
+	return &req, &Message{Type: t, Payload: req.Params}, nil
+}
+
+// rpcID returns the id to answer with
+func (r *rpcRequest) rpcID() json.RawMessage {
+	if r.ID == nil {
+		return rpcNull
+	}
+	return r.ID
+}
+
+// notification reports whether the request expects no response
+func (r *rpcRequest) notification() bool {
+	return r.ID == nil
+}
+
+// rpcReply converts the native response to req into a JSON-RPC one
+func rpcReply(req *rpcRequest, resp *Message) *rpcResponse {
+	id := req.rpcID()
+	if resp.Type != MsgError {
+		result := resp.Payload
+		if len(result) == 0 {
+			result = rpcNull
+		}
+		return &rpcResponse{JSONRPC: "2.0", Result: result, ID: id}
+	}
+
+	var payload ErrorPayload
+	resp.ParsePayload(&payload)
+	code := rpcServerError
+	switch payload.Code {
+	case CodeParseError:
+		code = rpcParseError
+	case CodeUnknownType:
+		code = rpcMethodNotFound
+		payload.Message = "method not found: " + req.Method
+	case CodeInvalidPayload:
+		code = rpcInvalidParams
+		// What native clients call the payload is params here.
+		if rest, ok := strings.CutPrefix(payload.Message, "payload"); ok {
+			payload.Message = "params" + rest
+		}
+	}
+	return rpcFailure(id, code, payload.Code, payload.Message)
+}
+
+func rpcFailure(id json.RawMessage, code int, canvasCode, message string) *rpcResponse {
+	return &rpcResponse{
+		JSONRPC: "2.0",
+		Error:   &rpcError{Code: code, Message: message, Data: rpcErrorData{Code: canvasCode}},
+		ID:      id,
+	}
+}
+
+// rpcEvent converts an event into a JSON-RPC notification
+func rpcEvent(msg *Message) any {
+	return rpcNotification{JSONRPC: "2.0", Method: rpcMethod(msg.Type), Params: msg.Payload}
+}
+
+// handleRPC answers a JSON-RPC request or batch. For canvas.subscribe it
+// answers nothing and returns subscribe, with the acknowledgement to send
+// unless the request was a notification. An error means the response
+// could not be written.
+func (s *Server) handleRPC(conn net.Conn, enc *json.Encoder, line []byte) (subscribe bool, ack any, err error) {
+	line = bytes.TrimSpace(line)
+	if line[0] == '[' {
+		return false, nil, s.handleRPCBatch(conn, enc, line)
+	}
+
+	req, msg, failure := parseRPC(line)
+	if failure != nil {
+		return false, nil, s.send(enc, failure)
+	}
+	switch msg.Type {
+	case MsgSubscribe:
+		if req.notification() {
+			return true, nil, nil
+		}
+		return true, &rpcResponse{JSONRPC: "2.0", Result: rpcNull, ID: req.ID}, nil
+	case MsgAuth:
+		return false, nil, s.send(enc, rpcFailure(req.rpcID(), rpcInvalidRequest, CodeInvalidPayload, "canvas.auth must be the first request"))
+	}
+
+	resp := s.respond(conn, msg)
+	if req.notification() {
+		return false, nil, nil
+	}
+	return false, nil, s.send(enc, rpcReply(req, resp))
+}
+
+// handleRPCBatch runs the requests of a JSON-RPC batch as one canvas batch,
+// with no other request in between, and answers with an array
+func (s *Server) handleRPCBatch(conn net.Conn, enc *json.Encoder, line []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(line, &raws); err != nil {
+		return s.send(enc, rpcFailure(rpcNull, rpcParseError, CodeParseError, err.Error()))
+	}
+	if len(raws) == 0 || len(raws) > MaxBatchSize {
+		return s.send(enc, rpcFailure(rpcNull, rpcInvalidRequest, CodeInvalidPayload,
+			fmt.Sprintf("batch must have 1 to %d requests", MaxBatchSize)))
+	}
+
+	replies := make([]*rpcResponse, len(raws))
+	reqs := make([]*rpcRequest, len(raws))
+	var batch BatchPayload
+	var slots []int // index in raws of each batched message
+	for i, raw := range raws {
+		req, msg, failure := parseRPC(raw)
+		switch {
+		case failure != nil:
+			replies[i] = failure
+		case msg.Type == MsgSubscribe || msg.Type == MsgAuth || msg.Type == MsgBatch:
+			replies[i] = rpcFailure(req.rpcID(), rpcInvalidRequest, CodeInvalidPayload,
+				fmt.Sprintf("%s cannot be batched", req.Method))
+		default:
+			reqs[i] = req
+			batch.Messages = append(batch.Messages, *msg)
+			slots = append(slots, i)
+		}
+	}
+
+	if len(batch.Messages) > 0 {
+		msg, _ := NewMessage(MsgBatch, batch)
+		resp := s.respond(conn, msg)
+		var result BatchResultPayload
+		if resp.Type != MsgBatchResult || resp.ParsePayload(&result) != nil || len(result.Responses) != len(slots) {
+			for _, i := range slots {
+				replies[i] = rpcReply(reqs[i], resp)
+			}
+		} else {
+			for n, i := range slots {
+				replies[i] = rpcReply(reqs[i], &result.Responses[n])
+			}
+		}
+	}
+
+	// Notifications are run but not answered; a batch of only
+	// notifications gets no response at all.
+	var out []*rpcResponse
+	for i, reply := range replies {
+		if reqs[i] != nil && reqs[i].notification() {
+			continue
+		}
+		out = append(out, reply)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return s.send(enc, out)
+}
@@ -0,0 +1,157 @@
+package canvas
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRPCMethodNames(t *testing.T) {
+	tests := []struct {
+		t      MessageType
+		method string
+	}{
+		{MsgGetState, "canvas.getState"},
+		{MsgGetStateSchema, "canvas.getStateSchema"},
+		{MsgSendKey, "canvas.sendKey"},
+		{MsgClose, "canvas.close"},
+		{MsgViewChunk, "canvas.viewChunk"},
+		{"undo_last", "canvas.undoLast"},
+	}
+	for _, tt := range tests {
+		if got := rpcMethod(tt.t); got != tt.method {
+			t.Errorf("rpcMethod(%q) = %q, want %q", tt.t, got, tt.method)
+		}
+		if got := rpcMessageType(tt.method); got != tt.t {
+			t.Errorf("rpcMessageType(%q) = %q, want %q", tt.method, got, tt.t)
+		}
+	}
+
+	// A leading capital does not add an underscore.
+	if got := rpcMessageType("canvas.GetState"); got != MsgGetState {
+		t.Errorf("rpcMessageType(%q) = %q, want %q", "canvas.GetState", got, MsgGetState)
+	}
+
+	for _, method := range []string{"getState", "canvas.", "other.getState", ""} {
+		if got := rpcMessageType(method); got != "" {
+			t.Errorf("rpcMessageType(%q) = %q, want none", method, got)
+		}
+	}
+}
+
+func TestIsJSONRPC(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"jsonrpc": "2.0", "method": "canvas.getState", "id": 1}`, true},
+		{`{"method": "canvas.getState"}`, true},
+		{` [{"jsonrpc": "2.0"}]`, true},
+		{`{"type": "get_state"}`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := isJSONRPC([]byte(tt.line)); got != tt.want {
+			t.Errorf("isJSONRPC(%s) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// keyModel records the keys it is sent
+type keyModel struct {
+	keys chan string
+}
+
+func (m *keyModel) CanvasState() StatePayload { return StatePayload{Mode: "rpc"} }
+
+func (m *keyModel) HandleCanvasKey(key string, r rune) error {
+	m.keys <- key
+	return nil
+}
+
+func TestJSONRPC(t *testing.T) {
+	m := &keyModel{keys: make(chan string, 4)}
+	s := newTestServer(t, m)
+	conn, err := net.Dial("unix", s.SocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	roundTrip := func(request string) string {
+		t.Helper()
+		if _, err := conn.Write([]byte(request + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return line
+	}
+	decode := func(line string, v any) {
+		t.Helper()
+		if err := json.Unmarshal([]byte(line), v); err != nil {
+			t.Fatalf("invalid response %s: %v", line, err)
+		}
+	}
+
+	var resp rpcResponse
+	decode(roundTrip(`{"jsonrpc": "2.0", "method": "canvas.getState", "id": "a"}`), &resp)
+	var state StatePayload
+	json.Unmarshal(resp.Result, &state)
+	if string(resp.ID) != `"a"` || resp.Error != nil || state.Mode != "rpc" {
+		t.Errorf("getState: got %+v", resp)
+	}
+
+	tests := []struct {
+		request string
+		code    int
+		canvas  string
+		id      string
+	}{
+		{`{"jsonrpc": "2.0", "method": "canvas.noSuchThing", "id": 1}`, rpcMethodNotFound, CodeUnknownType, "1"},
+		{`{"jsonrpc": "2.0", "method": "other", "id": 2}`, rpcMethodNotFound, CodeUnknownType, "2"},
+		{`{"jsonrpc": "2.0", "method": "canvas.sendKey", "params": {"key": 1}, "id": 3}`, rpcInvalidParams, CodeInvalidPayload, "3"},
+		{`{"jsonrpc": "2.0", "method": "canvas.getView", "id": 4}`, rpcServerError, CodeNotSupported, "4"},
+		{`{"jsonrpc": "1.0", "method": "canvas.getState", "id": 5}`, rpcInvalidRequest, CodeInvalidPayload, "5"},
+		{`{"jsonrpc": "2.0", "method": "canvas.auth", "params": {"token": "x"}, "id": 6}`, rpcInvalidRequest, CodeInvalidPayload, "6"},
+	}
+	for _, tt := range tests {
+		var resp rpcResponse
+		decode(roundTrip(tt.request), &resp)
+		if resp.Error == nil || resp.Error.Code != tt.code || resp.Error.Data.Code != tt.canvas || string(resp.ID) != tt.id {
+			t.Errorf("%s: got %+v, want code %d (%s) for id %s", tt.request, resp.Error, tt.code, tt.canvas, tt.id)
+		}
+	}
+
+	// Notifications run without an answer, so the next line answers the
+	// batch; its notification is run but left out.
+	conn.Write([]byte(`{"jsonrpc": "2.0", "method": "canvas.sendKey", "params": {"key": "up"}}` + "\n"))
+	var replies []rpcResponse
+	decode(roundTrip(`[{"jsonrpc": "2.0", "method": "canvas.sendKey", "params": {"key": "down"}, "id": 1},`+
+		`{"jsonrpc": "2.0", "method": "canvas.sendKey", "params": {"key": "left"}},`+
+		`{"jsonrpc": "2.0", "method": "canvas.subscribe", "id": 2},`+
+		`{"jsonrpc": "2.0", "method": "canvas.getState", "id": 3}]`), &replies)
+	if len(replies) != 3 {
+		t.Fatalf("got %d replies, want 3", len(replies))
+	}
+	if string(replies[0].ID) != "1" || replies[0].Error != nil || string(replies[0].Result) != "null" {
+		t.Errorf("sendKey in batch: got %+v", replies[0])
+	}
+	if string(replies[1].ID) != "2" || replies[1].Error == nil || replies[1].Error.Code != rpcInvalidRequest {
+		t.Errorf("subscribe in batch: got %+v", replies[1])
+	}
+	if string(replies[2].ID) != "3" || replies[2].Error != nil {
+		t.Errorf("getState in batch: got %+v", replies[2])
+	}
+	for _, want := range []string{"up", "down", "left"} {
+		if got := <-m.keys; got != want {
+			t.Errorf("key %q, want %q", got, want)
+		}
+	}
+}
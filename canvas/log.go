@@ -0,0 +1,35 @@
+package canvas
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// defaultLogger is the logger of servers, clients and adapters given none.
+// It writes to the file named by CANVAS_LOG, never to the terminal, which
+// belongs to the TUI, and discards everything if the variable is unset or
+// the file cannot be opened. CANVAS_LOG_LEVEL sets the level: debug, info
+// (the default), warn or error.
+var defaultLogger = sync.OnceValue(func() *slog.Logger {
+	path := os.Getenv("CANVAS_LOG")
+	if path == "" {
+		return discardLogger()
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return discardLogger()
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(os.Getenv("CANVAS_LOG_LEVEL")))); err != nil {
+		level = slog.LevelInfo
+	}
+	return slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: level})).With("pid", os.Getpid())
+})
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
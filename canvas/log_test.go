@@ -0,0 +1,63 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// logRecorder collects JSON log records
+type logRecorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (r *logRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *logRecorder) logger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(r, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// find returns the first record with the message msg
+func (r *logRecorder) find(msg string) map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, line := range bytes.Split(r.buf.Bytes(), []byte("\n")) {
+		var record map[string]any
+		if json.Unmarshal(line, &record) == nil && record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestRequestLogging(t *testing.T) {
+	serverLog, clientLog := &logRecorder{}, &logRecorder{}
+	s := newTestServer(t, stateModel("x"), WithLogger(serverLog.logger()))
+	c, _ := Dial(s.SocketPath(), WithClientLogger(clientLog.logger()))
+
+	c.GetState()
+	c.GetView()
+
+	handled := serverLog.find("canvas request handled")
+	if handled == nil || handled["type"] != "get_state" || handled["id"] != "test" {
+		t.Errorf("handled request logged as %v", handled)
+	}
+	failed := serverLog.find("canvas request failed")
+	if failed == nil || failed["type"] != "get_view" || failed["code"] != CodeNotSupported || failed["level"] != "INFO" {
+		t.Errorf("failed request logged as %v", failed)
+	}
+
+	if done := clientLog.find("canvas request done"); done == nil || done["type"] != "get_state" {
+		t.Errorf("client logged %v", done)
+	}
+	if failed := clientLog.find("canvas request failed"); failed == nil || failed["type"] != "get_view" {
+		t.Errorf("client logged %v for the failure", failed)
+	}
+}
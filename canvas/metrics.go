@@ -0,0 +1,238 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBounds are the upper bounds, in seconds, of the latency buckets
+var latencyBounds = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// unknownType collects the requests of types without a handler, so that
+// clients cannot grow the metrics without bound
+const unknownType MessageType = "unknown"
+
+// metrics collects what Server.Stats reports
+type metrics struct {
+	started     time.Time
+	connections atomic.Uint64
+	bytesIn     atomic.Uint64
+	bytesOut    atomic.Uint64
+
+	mu       sync.Mutex
+	messages map[MessageType]*messageMetrics
+}
+
+type messageMetrics struct {
+	count   uint64
+	errors  uint64
+	buckets []uint64 // per bound, not cumulative; the last is for slower requests
+	sum     time.Duration
+}
+
+func newMetrics() *metrics {
+	return &metrics{started: time.Now(), messages: make(map[MessageType]*messageMetrics)}
+}
+
+// observe records a handled request
+func (m *metrics) observe(t MessageType, failed bool, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm := m.messages[t]
+	if mm == nil {
+		mm = &messageMetrics{buckets: make([]uint64, len(latencyBounds)+1)}
+		m.messages[t] = mm
+	}
+	mm.count++
+	if failed {
+		mm.errors++
+	}
+	mm.sum += elapsed
+	i := sort.SearchFloat64s(latencyBounds, elapsed.Seconds())
+	mm.buckets[i]++
+}
+
+// metricsMiddleware counts every request, those inside batches included,
+// and how long it took
+func (s *Server) metricsMiddleware(next HandlerFunc) HandlerFunc {
+	return func(req *Request) *Message {
+		start := time.Now()
+		resp := next(req)
+		elapsed := time.Since(start)
+
+		t := req.Type
+		var payload ErrorPayload
+		failed := resp.Type == MsgError
+		if failed && resp.ParsePayload(&payload) == nil && payload.Code == CodeUnknownType {
+			t = unknownType
+		}
+		s.metrics.observe(t, failed, elapsed)
+		return resp
+	}
+}
+
+// Stats returns the metrics collected since the server started
+func (s *Server) Stats() StatsPayload {
+	s.mu.RLock()
+	active := len(s.conns)
+	s.mu.RUnlock()
+
+	m := s.metrics
+	stats := StatsPayload{
+		Canvas:            s.id,
+		StartedAt:         m.started,
+		ActiveConnections: active,
+		TotalConnections:  m.connections.Load(),
+		BytesIn:           m.bytesIn.Load(),
+		BytesOut:          m.bytesOut.Load(),
+		Messages:          make(map[MessageType]MessageStats),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for t, mm := range m.messages {
+		hist := LatencyHistogram{
+			Bounds: latencyBounds,
+			Counts: make([]uint64, len(latencyBounds)),
+			Sum:    mm.sum.Seconds(),
+		}
+		var total uint64
+		for i := range latencyBounds {
+			total += mm.buckets[i]
+			hist.Counts[i] = total
+		}
+		stats.Messages[t] = MessageStats{Count: mm.count, Errors: mm.errors, Latency: hist}
+	}
+	return stats
+}
+
+func handleStats(req *Request) *Message {
+	return req.Reply(MsgStatsResult, req.server.Stats())
+}
+
+// Quantile estimates the q-th quantile (0 to 1) of the latencies in
+// seconds as the upper bound of the bucket it falls in, +Inf if that is
+// beyond the largest bound and NaN if nothing was observed
+func (ms MessageStats) Quantile(q float64) float64 {
+	if ms.Count == 0 {
+		return math.NaN()
+	}
+	rank := q * float64(ms.Count)
+	for i, c := range ms.Latency.Counts {
+		if float64(c) >= rank {
+			return ms.Latency.Bounds[i]
+		}
+	}
+	return math.Inf(1)
+}
+
+// labelEscaper escapes label values as the Prometheus text format wants
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label formats a label pair
+func label(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
+
+// WritePrometheus writes the stats in the Prometheus text exposition
+// format
+func (p *StatsPayload) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+	canvas := label("canvas", p.Canvas)
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("canvas_start_time_seconds", "gauge", "When the canvas server started, in seconds since the epoch.")
+	fmt.Fprintf(&b, "canvas_start_time_seconds{%s} %d\n", canvas, p.StartedAt.Unix())
+	metric("canvas_connections_active", "gauge", "Open client connections.")
+	fmt.Fprintf(&b, "canvas_connections_active{%s} %d\n", canvas, p.ActiveConnections)
+	metric("canvas_connections_total", "counter", "Client connections accepted.")
+	fmt.Fprintf(&b, "canvas_connections_total{%s} %d\n", canvas, p.TotalConnections)
+	metric("canvas_received_bytes_total", "counter", "Bytes received from clients.")
+	fmt.Fprintf(&b, "canvas_received_bytes_total{%s} %d\n", canvas, p.BytesIn)
+	metric("canvas_sent_bytes_total", "counter", "Bytes sent to clients.")
+	fmt.Fprintf(&b, "canvas_sent_bytes_total{%s} %d\n", canvas, p.BytesOut)
+
+	types := make([]MessageType, 0, len(p.Messages))
+	for t := range p.Messages {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	metric("canvas_requests_total", "counter", "Requests handled, by type.")
+	for _, t := range types {
+		fmt.Fprintf(&b, "canvas_requests_total{%s,%s} %d\n", canvas, label("type", string(t)), p.Messages[t].Count)
+	}
+	metric("canvas_request_errors_total", "counter", "Requests answered with an error, by type.")
+	for _, t := range types {
+		fmt.Fprintf(&b, "canvas_request_errors_total{%s,%s} %d\n", canvas, label("type", string(t)), p.Messages[t].Errors)
+	}
+	metric("canvas_request_duration_seconds", "histogram", "Time taken to handle requests, by type.")
+	for _, t := range types {
+		ms := p.Messages[t]
+		labels := canvas + "," + label("type", string(t))
+		for i, bound := range ms.Latency.Bounds {
+			fmt.Fprintf(&b, "canvas_request_duration_seconds_bucket{%s,%s} %d\n",
+				labels, label("le", strconv.FormatFloat(bound, 'g', -1, 64)), ms.Latency.Counts[i])
+		}
+		fmt.Fprintf(&b, "canvas_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, ms.Count)
+		fmt.Fprintf(&b, "canvas_request_duration_seconds_sum{%s} %g\n", labels, ms.Latency.Sum)
+		fmt.Fprintf(&b, "canvas_request_duration_seconds_count{%s} %d\n", labels, ms.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// serveMetrics serves the stats in Prometheus format on /metrics
+func (s *Server) serveMetrics(l net.Listener) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		stats := s.Stats()
+		stats.WritePrometheus(w)
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: authTimeout}
+	if err := srv.Serve(l); err != nil && !errors.Is(err, net.ErrClosed) {
+		select {
+		case <-s.done:
+		default:
+			s.reportError(fmt.Errorf("metrics listener failed: %w", err))
+		}
+	}
+}
+
+// countingReader and countingWriter add the bytes passing through to a
+// counter
+type countingReader struct {
+	r io.Reader
+	n *atomic.Uint64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Uint64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
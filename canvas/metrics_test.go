@@ -0,0 +1,102 @@
+package canvas
+
+import (
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuantile(t *testing.T) {
+	m := newMetrics()
+	for _, d := range []time.Duration{50 * time.Microsecond, 3 * time.Millisecond, 3 * time.Millisecond, 10 * time.Second} {
+		m.observe(MsgGetState, false, d)
+	}
+	s := &Server{metrics: m, conns: map[net.Conn]struct{}{}}
+	ms := s.Stats().Messages[MsgGetState]
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 0.0001},
+		{0.25, 0.0001},
+		{0.5, 0.005},
+		{0.75, 0.005},
+		{1, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if got := ms.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if got := (MessageStats{}).Quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("Quantile of no observations = %v, want NaN", got)
+	}
+}
+
+func TestWritePrometheusLabels(t *testing.T) {
+	m := newMetrics()
+	m.observe(`say "hi"\`+"\n", true, time.Millisecond)
+	s := &Server{id: "ünï\tcode", metrics: m, conns: map[net.Conn]struct{}{}}
+	stats := s.Stats()
+
+	var b strings.Builder
+	if err := stats.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	want := `canvas_request_errors_total{canvas="ünï	code",type="say \"hi\"\\\n"} 1`
+	if !strings.Contains(out, want+"\n") {
+		t.Errorf("missing %s in\n%s", want, out)
+	}
+	if strings.Contains(out, `\u`) || strings.Contains(out, `\t`) {
+		t.Errorf("Go escapes in\n%s", out)
+	}
+}
+
+func TestStats(t *testing.T) {
+	s := newTestServer(t, stateModel("x"), WithMetricsAddr("127.0.0.1:0"))
+	c := NewClientWithSocket(s.SocketPath())
+
+	c.GetState()
+	c.GetView()
+	c.Call("bogus", nil, nil)
+	getState, _ := NewMessage(MsgGetState, nil)
+	c.Batch(getState)
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[MessageType]MessageStats{
+		MsgGetState: {Count: 2},
+		MsgGetView:  {Count: 1, Errors: 1},
+		unknownType: {Count: 1, Errors: 1},
+		MsgBatch:    {Count: 1},
+	}
+	for typ, w := range want {
+		if got := stats.Messages[typ]; got.Count != w.Count || got.Errors != w.Errors {
+			t.Errorf("%s: %d requests, %d errors, want %d, %d", typ, got.Count, got.Errors, w.Count, w.Errors)
+		}
+	}
+	if _, ok := stats.Messages["bogus"]; ok {
+		t.Error("an unknown type has metrics of its own")
+	}
+	if stats.TotalConnections == 0 || stats.BytesIn == 0 || stats.BytesOut == 0 {
+		t.Errorf("connections and bytes not counted: %+v", stats)
+	}
+
+	resp, err := http.Get(s.Metadata().MetricsURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if line := `canvas_requests_total{canvas="test",type="get_state"} 2`; !strings.Contains(string(body), line+"\n") {
+		t.Errorf("missing %s in\n%s", line, body)
+	}
+}
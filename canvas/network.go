@@ -0,0 +1,146 @@
+package canvas
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// authTimeout bounds how long a network client may take to authenticate
+const authTimeout = 10 * time.Second
+
+// remoteListener is a TCP or WebSocket listener serving the canvas next to
+// its Unix socket
+type remoteListener struct {
+	net.Listener
+	scheme string // tcp, tls, ws or wss
+}
+
+// url returns the address clients pass to Dial
+func (l *remoteListener) url(id string) string {
+	return fmt.Sprintf("%s://%s/%s", l.scheme, l.Addr(), id)
+}
+
+// listenRemote opens the listeners requested with WithListen
+func listenRemote(o *serverOptions) ([]*remoteListener, error) {
+	var listeners []*remoteListener
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+
+	for _, addr := range o.listen {
+		u, err := url.Parse(addr)
+		if err != nil || u.Host == "" {
+			closeAll()
+			return nil, fmt.Errorf("invalid listen address '%s'", addr)
+		}
+		if u.Scheme != "tcp" && u.Scheme != "ws" {
+			closeAll()
+			return nil, fmt.Errorf("unsupported listen scheme '%s': use tcp:// or ws://", u.Scheme)
+		}
+
+		ln, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to listen on %s: %w", u.Host, err)
+		}
+		l := &remoteListener{Listener: ln, scheme: u.Scheme}
+		if o.tls != nil {
+			l.Listener = tls.NewListener(ln, o.tls)
+			l.scheme = map[string]string{"tcp": "tls", "ws": "wss"}[u.Scheme]
+		}
+		if o.token == "" && !isLoopback(ln.Addr()) {
+			if !o.insecureListen {
+				ln.Close()
+				closeAll()
+				return nil, fmt.Errorf("refusing to listen on %s without a token: use WithToken, or WithInsecureListen to allow it", u.Host)
+			}
+			o.logger.Warn("canvas reachable from the network without a token", "addr", l.Addr().String())
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// serveWebSocket upgrades requests for "/" or "/<id>" and serves them like
+// any other connection
+func (s *Server) serveWebSocket(l *remoteListener) {
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" && r.URL.Path != "/"+s.id {
+				http.NotFound(w, r)
+				return
+			}
+			conn, err := upgradeWebSocket(w, r, s.opts.maxMessageSize)
+			if err != nil {
+				s.log.Debug("canvas WebSocket upgrade failed", "err", err)
+				return
+			}
+			s.handleConnection(conn, true)
+		}),
+		ReadHeaderTimeout: authTimeout,
+	}
+	if err := srv.Serve(l); err != nil && !errors.Is(err, net.ErrClosed) {
+		select {
+		case <-s.done:
+		default:
+			s.reportError(fmt.Errorf("WebSocket listener failed: %w", err))
+		}
+	}
+}
+
+// authenticate checks the auth message a network client must send first,
+// natively or as a canvas.auth JSON-RPC request
+func (s *Server) authenticate(conn net.Conn, reader *bufio.Reader, enc *json.Encoder) bool {
+	conn.SetReadDeadline(time.Now().Add(authTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	line, err := readMessage(reader, s.opts.maxMessageSize)
+	if err != nil {
+		return false
+	}
+
+	var msg Message
+	var rpcID json.RawMessage // set for JSON-RPC clients
+	if isJSONRPC(line) {
+		rpcID = rpcNull
+		if req, m, failure := parseRPC(line); failure == nil {
+			msg, rpcID = *m, req.rpcID()
+		}
+	} else {
+		json.Unmarshal(line, &msg)
+	}
+
+	var auth AuthPayload
+	if msg.Type != MsgAuth || validatePayload(&msg) != nil || msg.ParsePayload(&auth) != nil ||
+		subtle.ConstantTimeCompare([]byte(auth.Token), []byte(s.opts.token)) != 1 {
+		s.log.Warn("canvas client failed to authenticate", "remote", conn.RemoteAddr().String())
+		const reason = "a valid auth message must be sent first"
+		if rpcID != nil {
+			s.send(enc, rpcFailure(rpcID, rpcServerError, CodeUnauthorized, reason))
+		} else {
+			s.sendError(enc, CodeUnauthorized, reason)
+		}
+		return false
+	}
+
+	if rpcID != nil {
+		return s.send(enc, &rpcResponse{JSONRPC: "2.0", Result: rpcNull, ID: rpcID}) == nil
+	}
+	resp, _ := NewMessage(MsgAck, nil)
+	return s.send(enc, resp) == nil
+}
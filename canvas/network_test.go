@@ -0,0 +1,74 @@
+package canvas
+
+import (
+	"strings"
+	"testing"
+)
+
+// newNetworkServer starts a canvas with the given listen options
+func newNetworkServer(t *testing.T, opts ...ServerOption) *Server {
+	t.Helper()
+	s, err := NewServer("net", append([]ServerOption{WithSocketDir(t.TempDir())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetModel(stateModel("remote"))
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestRemoteToken(t *testing.T) {
+	for _, scheme := range []string{"tcp", "ws"} {
+		t.Run(scheme, func(t *testing.T) {
+			s := newNetworkServer(t, WithListen(scheme+"://127.0.0.1:0"), WithToken("secret"))
+			addr := s.Metadata().Addrs[0]
+			if !strings.HasPrefix(addr, scheme+"://") || !strings.HasSuffix(addr, "/net") {
+				t.Fatalf("advertised address %q", addr)
+			}
+
+			c, err := Dial(addr + "?token=secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			state, err := c.GetState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.Mode != "remote" {
+				t.Errorf("got mode %q", state.Mode)
+			}
+
+			t.Setenv("CANVAS_TOKEN", "")
+			c, _ = Dial(addr + "?token=wrong")
+			if _, err := c.GetState(); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+				t.Errorf("got %v with a wrong token, want unauthorized", err)
+			}
+		})
+	}
+}
+
+func TestListenWithoutToken(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ServerOption
+		ok   bool
+	}{
+		{"loopback", []ServerOption{WithListen("tcp://127.0.0.1:0")}, true},
+		{"any address", []ServerOption{WithListen("tcp://0.0.0.0:0")}, false},
+		{"any address with a token", []ServerOption{WithListen("ws://0.0.0.0:0"), WithToken("secret")}, true},
+		{"any address opted in", []ServerOption{WithListen("tcp://0.0.0.0:0"), WithInsecureListen()}, true},
+		{"second address", []ServerOption{WithListen("tcp://127.0.0.1:0"), WithListen("ws://0.0.0.0:0")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewServer("net", append([]ServerOption{WithSocketDir(t.TempDir())}, tt.opts...)...)
+			if err == nil {
+				s.Stop()
+			}
+			if ok := err == nil; ok != tt.ok {
+				t.Errorf("NewServer error = %v, want success %v", err, tt.ok)
+			}
+		})
+	}
+}
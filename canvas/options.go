@@ -0,0 +1,243 @@
+package canvas
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// ServerOption configures a Server created by NewServer or WrapWithOptions
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	autoSuffix     bool
+	socketDir      string
+	socketMode     os.FileMode
+	logger         *slog.Logger
+	idleTimeout    time.Duration
+	writeTimeout   time.Duration
+	maxConnections int
+	maxMessageSize int
+	envGate        []string
+	onError        func(error)
+	listen         []string
+	tls            *tls.Config
+	token          string
+	insecureListen bool
+	handlers       map[MessageType]HandlerFunc
+	middleware     []Middleware
+	auditLog       string
+	redactor       Redactor
+	metricsAddr    string
+}
+
+// Default limits of a Server, each adjustable with the matching option
+const (
+	DefaultIdleTimeout    = 5 * time.Minute
+	DefaultWriteTimeout   = 30 * time.Second
+	DefaultMaxConnections = 64
+	DefaultMaxMessageSize = 1 << 20
+)
+
+// newServerOptions applies opts over the defaults
+func newServerOptions(opts []ServerOption) *serverOptions {
+	o := &serverOptions{
+		socketDir:      DefaultSocketDir(),
+		logger:         defaultLogger(),
+		idleTimeout:    DefaultIdleTimeout,
+		writeTimeout:   DefaultWriteTimeout,
+		maxConnections: DefaultMaxConnections,
+		maxMessageSize: DefaultMaxMessageSize,
+		envGate:        []string{"OPENCODE_CANVAS", "CANVAS_ID"},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAutoSuffix makes NewServer pick the first free ID of the form
+// "<id>-2", "<id>-3", ... when the requested ID belongs to a running canvas,
+// instead of failing with ErrIDInUse. Server.ID reports the chosen ID.
+func WithAutoSuffix() ServerOption {
+	return func(o *serverOptions) {
+		o.autoSuffix = true
+	}
+}
+
+// WithSocketDir puts the socket and metadata in dir instead of
+// DefaultSocketDir. Canvases outside the default directory are not found
+// by List or NewClient; connect to them with NewClientWithSocket.
+func WithSocketDir(dir string) ServerOption {
+	return func(o *serverOptions) {
+		o.socketDir = dir
+	}
+}
+
+// WithSocketMode sets the permissions of the socket file, e.g. 0600 to
+// keep other users from connecting
+func WithSocketMode(mode os.FileMode) ServerOption {
+	return func(o *serverOptions) {
+		o.socketMode = mode
+	}
+}
+
+// WithLogger makes the server log connections, requests and errors to
+// logger. By default it logs to the file named by CANVAS_LOG, if any.
+func WithLogger(logger *slog.Logger) ServerOption {
+	return func(o *serverOptions) {
+		o.logger = logger
+	}
+}
+
+// WithIdleTimeout closes connections that do not complete a request within
+// d of connecting or of their previous request, DefaultIdleTimeout by
+// default. Subscriptions are exempt; zero disables the timeout.
+func WithIdleTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.idleTimeout = d
+	}
+}
+
+// WithWriteTimeout bounds how long writing a response or event may take,
+// DefaultWriteTimeout by default; zero disables the timeout
+func WithWriteTimeout(d time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// WithMaxConnections limits the number of simultaneous connections,
+// subscriptions included, to n (DefaultMaxConnections by default; zero
+// for no limit). Clients beyond the limit get a too_many_connections
+// error.
+func WithMaxConnections(n int) ServerOption {
+	return func(o *serverOptions) {
+		o.maxConnections = n
+	}
+}
+
+// WithMaxMessageSize limits requests to n bytes (DefaultMaxMessageSize by
+// default; zero for no limit). A client sending a larger one gets a
+// message_too_large error and is disconnected, since the rest of its
+// stream cannot be trusted to be in sync. WebSocket messages are limited
+// to 64 MiB regardless.
+func WithMaxMessageSize(n int) ServerOption {
+	return func(o *serverOptions) {
+		o.maxMessageSize = n
+	}
+}
+
+// WithEnvGate makes WrapWithOptions enable the canvas only if one of the
+// environment variables is set (OPENCODE_CANVAS or CANVAS_ID by default).
+// Without variables the canvas is always enabled.
+func WithEnvGate(vars ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.envGate = vars
+	}
+}
+
+// WithErrorHandler sets a callback for errors that cannot be returned,
+// such as a canvas failing to start inside WrapWithOptions or a failed
+// accept
+func WithErrorHandler(fn func(error)) ServerOption {
+	return func(o *serverOptions) {
+		o.onError = fn
+	}
+}
+
+// WithListen makes the canvas also reachable over the network, in addition
+// to its Unix socket. addr is "tcp://host:port" for the line protocol over
+// TCP or "ws://host:port" for one message per WebSocket frame; port 0
+// picks a free port. An address that is not loopback-only needs WithToken,
+// or WithInsecureListen; add WithTLS when the network is not trusted.
+func WithListen(addr string) ServerOption {
+	return func(o *serverOptions) {
+		o.listen = append(o.listen, addr)
+	}
+}
+
+// WithTLS serves network listeners over TLS (tls:// and wss://)
+func WithTLS(cfg *tls.Config) ServerOption {
+	return func(o *serverOptions) {
+		o.tls = cfg
+	}
+}
+
+// WithToken requires network clients to send an auth message carrying
+// token before anything else. Unix socket clients are not affected.
+func WithToken(token string) ServerOption {
+	return func(o *serverOptions) {
+		o.token = token
+	}
+}
+
+// WithInsecureListen lets WithListen serve an address that is not
+// loopback-only without WithToken. Anyone who can reach the address can
+// then read and control the canvas.
+func WithInsecureListen() ServerOption {
+	return func(o *serverOptions) {
+		o.insecureListen = true
+	}
+}
+
+// WithHandler registers fn for messages of type t, like Server.Handle, for
+// servers created by WrapWithOptions
+func WithHandler(t MessageType, fn HandlerFunc) ServerOption {
+	return func(o *serverOptions) {
+		if o.handlers == nil {
+			o.handlers = make(map[MessageType]HandlerFunc)
+		}
+		o.handlers[t] = fn
+	}
+}
+
+// WithMiddleware adds middleware around every request, like Server.Use,
+// for servers created by WrapWithOptions
+func WithMiddleware(mw ...Middleware) ServerOption {
+	return func(o *serverOptions) {
+		o.middleware = append(o.middleware, mw...)
+	}
+}
+
+// WithAuditLog appends a JSON line to the file at path for every request
+// that acts on the app: send_key, send_input, close and custom types. Each
+// AuditRecord has the time, the client's PID and UID (local clients, on
+// Linux) or address, the redacted payload and the result. The path is
+// published in the registry for "opencode-canvas audit".
+func WithAuditLog(path string) ServerOption {
+	return func(o *serverOptions) {
+		o.auditLog = path
+	}
+}
+
+// WithAuditRedactor sets what the audit log records of payloads, instead
+// of RedactSecrets. Pass RedactInput to keep typed text out of the log too.
+func WithAuditRedactor(fn Redactor) ServerOption {
+	return func(o *serverOptions) {
+		o.redactor = fn
+	}
+}
+
+// WithMetricsAddr serves the server's stats in the Prometheus text format
+// on http://addr/metrics, e.g. "127.0.0.1:9100". The endpoint has no
+// authentication, so keep addr local.
+func WithMetricsAddr(addr string) ServerOption {
+	return func(o *serverOptions) {
+		o.metricsAddr = addr
+	}
+}
+
+// enabled reports whether the environment gate lets the canvas start
+func (o *serverOptions) enabled() bool {
+	if len(o.envGate) == 0 {
+		return true
+	}
+	for _, name := range o.envGate {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// DefaultParallel is how many canvases Overview queries at once by default
+const DefaultParallel = 8
+
+// Snapshot is what a canvas reported to Overview or Client.Snapshot
+type Snapshot struct {
+	State *StatePayload `json:"state,omitempty"`
+	View  *string       `json:"view,omitempty"`
+	Error string        `json:"error,omitempty"` // set if the canvas failed to answer
+
+	err error // the failures behind Error
+}
+
+// Err returns the failures described by Error, or nil, for use with
+// errors.Is and errors.As
+func (s *Snapshot) Err() error {
+	return s.err
+}
+
+// setErr records failures in both Err and Error
+func (s *Snapshot) setErr(errs ...error) {
+	s.err = errors.Join(errs...)
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	s.Error = strings.Join(msgs, "; ")
+}
+
+// OverviewOptions selects what Overview fetches from each canvas
+type OverviewOptions struct {
+	State    bool
+	View     bool
+	Parallel int // concurrent queries, DefaultParallel if zero
+}
+
+// Overview queries every live canvas concurrently and returns their
+// snapshots keyed by ID. A canvas that fails to answer gets an Error in its
+// snapshot rather than failing the whole call.
+func Overview(ctx context.Context, opts OverviewOptions) (map[string]*Snapshot, error) {
+	canvases, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, parallel)
+		snapshots = make(map[string]*Snapshot)
+	)
+	for _, c := range canvases {
+		if c.Status != StatusAlive {
+			continue
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			var snap *Snapshot
+			select {
+			case sem <- struct{}{}:
+				snap = snapshot(NewClient(id), opts)
+				<-sem
+			case <-ctx.Done():
+				snap = &Snapshot{}
+				snap.setErr(ctx.Err())
+			}
+			mu.Lock()
+			snapshots[id] = snap
+			mu.Unlock()
+		}(c.ID)
+	}
+	wg.Wait()
+
+	return snapshots, nil
+}
+
+// snapshot fetches what opts asks for from one canvas, keeping whatever
+// succeeded
+func snapshot(client *Client, opts OverviewOptions) *Snapshot {
+	if !opts.State && !opts.View {
+		return &Snapshot{}
+	}
+	snap, err := client.snapshot(opts.State, opts.View)
+	if err != nil {
+		snap = &Snapshot{}
+		snap.setErr(err)
+	}
+	return snap
+}
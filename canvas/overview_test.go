@@ -0,0 +1,95 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// busyModel records how many state requests it serves at once
+type busyModel struct {
+	mu          sync.Mutex
+	active, max int
+}
+
+func (m *busyModel) CanvasState() StatePayload {
+	m.mu.Lock()
+	m.active++
+	m.max = max(m.max, m.active)
+	m.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	m.mu.Lock()
+	m.active--
+	m.mu.Unlock()
+	return StatePayload{Mode: "busy"}
+}
+
+func TestOverview(t *testing.T) {
+	dir := tempSocketDir(t)
+	startServer(t, "full", viewStateModel("x"))
+	startServer(t, "state-only", stateModel("x"))
+
+	// A canvas whose process is gone is left out.
+	os.WriteFile(filepath.Join(dir, "dead.sock"), nil, 0644)
+	meta := &Metadata{ID: "dead", PID: deadPID(t), Socket: SocketPath("dead")}
+	if err := meta.write(); err != nil {
+		t.Fatal(err)
+	}
+
+	snaps, err := Overview(context.Background(), OverviewOptions{State: true, View: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for id := range snaps {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if want := []string{"full", "state-only"}; !slices.Equal(ids, want) {
+		t.Fatalf("got snapshots of %v, want %v", ids, want)
+	}
+
+	full := snaps["full"]
+	if full.Error != "" || full.State == nil || full.State.Mode != "x" || full.View == nil || *full.View != "view of x" {
+		t.Errorf("full snapshot = %+v", full)
+	}
+
+	// A failing request keeps what the canvas did answer.
+	partial := snaps["state-only"]
+	if partial.State == nil || partial.State.Mode != "x" || partial.View != nil || !strings.Contains(partial.Error, "not_supported") {
+		t.Errorf("state-only snapshot = %+v", partial)
+	}
+}
+
+func TestOverviewParallel(t *testing.T) {
+	tempSocketDir(t)
+	m := &busyModel{}
+	for i := range 6 {
+		startServer(t, fmt.Sprintf("busy-%d", i), m)
+	}
+
+	snaps, err := Overview(context.Background(), OverviewOptions{State: true, Parallel: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 6 {
+		t.Errorf("got %d snapshots, want 6", len(snaps))
+	}
+	if m.max != 2 {
+		t.Errorf("%d canvases queried at once, want 2", m.max)
+	}
+}
+
+// viewStateModel reports a fixed mode and a view derived from it
+type viewStateModel string
+
+func (m viewStateModel) CanvasState() StatePayload { return StatePayload{Mode: string(m)} }
+func (m viewStateModel) CanvasView() string        { return "view of " + string(m) }
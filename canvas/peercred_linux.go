@@ -0,0 +1,30 @@
+//go:build linux
+
+package canvas
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerCred returns the PID and UID of the process at the other end of a
+// Unix socket connection
+func peerCred(conn net.Conn) (pid, uid int, ok bool) {
+	uc, isUnix := conn.(*net.UnixConn)
+	if !isUnix {
+		return 0, 0, false
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		return 0, 0, false
+	}
+	return int(cred.Pid), int(cred.Uid), true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package canvas
+
+import "net"
+
+// peerCred is only implemented on Linux; elsewhere audit records of local
+// clients carry no PID or UID
+func peerCred(conn net.Conn) (pid, uid int, ok bool) {
+	return 0, 0, false
+}
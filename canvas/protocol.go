@@ -2,30 +2,42 @@
 // It allows AI assistants to query and control TUI state via Unix sockets.
 package canvas
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // MessageType identifies the type of IPC message
 type MessageType string
 
 const (
 	// Queries (AI → TUI)
-	MsgGetState    MessageType = "get_state"
-	MsgGetView     MessageType = "get_view"
-	MsgSendKey     MessageType = "send_key"
-	MsgSendInput   MessageType = "send_input"
-	MsgClose       MessageType = "close"
+	MsgGetState       MessageType = "get_state"
+	MsgGetView        MessageType = "get_view"
+	MsgGetStateSchema MessageType = "get_state_schema" // JSON Schema of the custom state
+	MsgSendKey        MessageType = "send_key"
+	MsgSendInput      MessageType = "send_input"
+	MsgClose          MessageType = "close"
+	MsgSubscribe      MessageType = "subscribe" // turns the connection into an event stream
+	MsgAuth           MessageType = "auth"      // first message on token-protected network connections
+	MsgBatch          MessageType = "batch"     // several requests answered together
+	MsgStats          MessageType = "stats"     // counters and latencies of the server
 
 	// Responses (TUI → AI)
 	MsgState       MessageType = "state"
 	MsgView        MessageType = "view"
+	MsgViewChunk   MessageType = "view_chunk" // part of a view requested in chunks
+	MsgStateSchema MessageType = "state_schema"
+	MsgBatchResult MessageType = "batch_result"
+	MsgStatsResult MessageType = "stats_result"
 	MsgAck         MessageType = "ack"
 	MsgError       MessageType = "error"
 
 	// Events (TUI → AI, async)
-	MsgReady       MessageType = "ready"
-	MsgUpdated     MessageType = "updated"
-	MsgSelected    MessageType = "selected"
-	MsgCancelled   MessageType = "cancelled"
+	MsgReady     MessageType = "ready"
+	MsgUpdated   MessageType = "updated"
+	MsgSelected  MessageType = "selected"
+	MsgCancelled MessageType = "cancelled"
 )
 
 // Message is the base IPC message structure
@@ -38,20 +50,39 @@ type Message struct {
 type StatePayload struct {
 	// Custom fields from the TUI's model
 	Custom map[string]any `json:"custom,omitempty"`
-	
+
 	// Standard fields
-	Width    int    `json:"width,omitempty"`
-	Height   int    `json:"height,omitempty"`
-	Focused  bool   `json:"focused,omitempty"`
-	Mode     string `json:"mode,omitempty"`
-	Input    string `json:"input,omitempty"`
-	Cursor   int    `json:"cursor,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Focused bool   `json:"focused,omitempty"`
+	Mode    string `json:"mode,omitempty"`
+	Input   string `json:"input,omitempty"`
+	Cursor  int    `json:"cursor,omitempty"`
+}
+
+// StateSchemaPayload contains the JSON Schema of StatePayload.Custom for
+// models implementing TypedStateProvider
+type StateSchemaPayload struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+// ViewRequestPayload optionally accompanies get_view
+type ViewRequestPayload struct {
+	// ChunkSize asks for views longer than this many bytes to be sent as
+	// view_chunk messages followed by a view with the remainder
+	ChunkSize int `json:"chunk_size,omitempty"`
 }
 
 // ViewPayload contains the rendered view
 type ViewPayload struct {
 	Content string `json:"content"`
-	ANSI    bool   `json:"ansi"` // true if content contains ANSI codes
+	ANSI    bool   `json:"ansi"`              // true if content contains ANSI codes
+	Chunked bool   `json:"chunked,omitempty"` // true if view_chunk messages carried the start of content
+}
+
+// ViewChunkPayload contains a part of a view, see ViewRequestPayload
+type ViewChunkPayload struct {
+	Content string `json:"content"`
 }
 
 // KeyPayload contains a key to send
@@ -65,6 +96,55 @@ type InputPayload struct {
 	Text string `json:"text"`
 }
 
+// ClosePayload reports the outcome of a close request. Servers that
+// neither run a CloseHandler nor an OnClose callback ack without it.
+type ClosePayload struct {
+	Exited bool   `json:"exited"`           // true if the app is shutting down
+	Reason string `json:"reason,omitempty"` // why the app stayed open
+}
+
+// AuthPayload authenticates a network connection
+type AuthPayload struct {
+	Token string `json:"token"`
+}
+
+// BatchPayload contains requests to run in order
+type BatchPayload struct {
+	Messages []Message `json:"messages"`
+}
+
+// BatchResultPayload contains the responses to a batch, one per request
+// and in the same order
+type BatchResultPayload struct {
+	Responses []Message `json:"responses"`
+}
+
+// StatsPayload contains the metrics a server has collected since it started
+type StatsPayload struct {
+	Canvas            string                       `json:"canvas"`
+	StartedAt         time.Time                    `json:"started_at"`
+	ActiveConnections int                          `json:"active_connections"`
+	TotalConnections  uint64                       `json:"total_connections"`
+	BytesIn           uint64                       `json:"bytes_in"`
+	BytesOut          uint64                       `json:"bytes_out"`
+	Messages          map[MessageType]MessageStats `json:"messages"` // by request type; unknown types count as "unknown"
+}
+
+// MessageStats contains the metrics of one request type
+type MessageStats struct {
+	Count   uint64           `json:"count"`
+	Errors  uint64           `json:"errors"` // error responses
+	Latency LatencyHistogram `json:"latency"`
+}
+
+// LatencyHistogram counts requests by how long they took to handle, in
+// the manner of a Prometheus histogram
+type LatencyHistogram struct {
+	Bounds []float64 `json:"bounds"` // upper bounds of the buckets in seconds
+	Counts []uint64  `json:"counts"` // requests within each bound, cumulative
+	Sum    float64   `json:"sum"`    // total seconds
+}
+
 // ErrorPayload contains error information
 type ErrorPayload struct {
 	Code    string `json:"code"`
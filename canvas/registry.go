@@ -0,0 +1,197 @@
+package canvas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Canvas statuses reported by List
+const (
+	StatusAlive = "alive"
+	StatusDead  = "dead"
+)
+
+// Capabilities advertised in the registry, one per interface or message
+// family the server can handle
+const (
+	CapState       = "state"
+	CapView        = "view"
+	CapKey         = "key"
+	CapInput       = "input"
+	CapClose       = "close"
+	CapSubscribe   = "subscribe"
+	CapStateSchema = "state_schema"
+	CapStats       = "stats"
+)
+
+// Metadata describes a running canvas. NewServer writes it next to the
+// socket so that canvases can be listed without connecting to each one.
+type Metadata struct {
+	ID           string    `json:"id"`
+	RequestedID  string    `json:"requested_id,omitempty"` // set when the ID was suffixed
+	PID          int       `json:"pid,omitempty"`
+	App          string    `json:"app,omitempty"`
+	Cwd          string    `json:"cwd,omitempty"`
+	Command      []string  `json:"command,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	TmuxPane     string    `json:"tmux_pane,omitempty"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+	Socket       string    `json:"socket"`
+	Addrs        []string  `json:"addrs,omitempty"`       // network addresses, see WithListen
+	AuditLog     string    `json:"audit_log,omitempty"`   // see WithAuditLog
+	MetricsURL   string    `json:"metrics_url,omitempty"` // see WithMetricsAddr
+}
+
+// Entry is a canvas found in the registry
+type Entry struct {
+	Metadata
+	Status string `json:"status"`
+}
+
+// MetadataPath returns the metadata file path for a canvas ID
+func MetadataPath(id string) string {
+	return filepath.Join(DefaultSocketDir(), fmt.Sprintf("%s.json", id))
+}
+
+// metadataFile returns the metadata file next to a canvas socket
+func metadataFile(socket string) string {
+	return strings.TrimSuffix(socket, ".sock") + ".json"
+}
+
+// ReadMetadata loads the registry metadata of a canvas
+func ReadMetadata(id string) (*Metadata, error) {
+	return readMetadataFile(MetadataPath(id))
+}
+
+func readMetadataFile(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		return nil, fmt.Errorf("invalid metadata for canvas '%s': %w", id, err)
+	}
+	return &meta, nil
+}
+
+// Alive reports whether the process that registered the canvas still runs
+func (m *Metadata) Alive() bool {
+	return m.PID > 0 && processAlive(m.PID)
+}
+
+// newMetadata describes the current process serving the given canvas
+func newMetadata(id, socket string) *Metadata {
+	cwd, _ := os.Getwd()
+	app := filepath.Base(os.Args[0])
+	if exe, err := os.Executable(); err == nil {
+		app = filepath.Base(exe)
+	}
+	return &Metadata{
+		ID:        id,
+		PID:       os.Getpid(),
+		App:       app,
+		Cwd:       cwd,
+		Command:   os.Args,
+		StartedAt: time.Now(),
+		TmuxPane:  os.Getenv("TMUX_PANE"),
+		Socket:    socket,
+	}
+}
+
+// write atomically replaces the metadata file so readers never see a
+// partial document
+func (m *Metadata) write() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := metadataFile(m.Socket)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// capabilitiesOf lists the capabilities a model supports
+func capabilitiesOf(model any) []string {
+	caps := []string{}
+	if _, ok := model.(StateProvider); ok {
+		caps = append(caps, CapState)
+	} else if _, ok := typedState(model); ok {
+		caps = append(caps, CapState)
+	}
+	if stateSchema(model) != nil {
+		caps = append(caps, CapStateSchema)
+	}
+	if _, ok := model.(ViewProvider); ok {
+		caps = append(caps, CapView)
+	}
+	if _, ok := model.(KeyHandler); ok {
+		caps = append(caps, CapKey)
+	}
+	if _, ok := model.(InputHandler); ok {
+		caps = append(caps, CapInput)
+	}
+	return append(caps, CapClose, CapSubscribe, CapStats)
+}
+
+// List returns every canvas in the socket directory. Canvases with
+// metadata are checked by PID liveness; sockets without metadata, such as
+// those of older servers, are pinged concurrently instead.
+func List() ([]Entry, error) {
+	entries, err := os.ReadDir(DefaultSocketDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read socket directory: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		canvases []Entry
+	)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".sock")
+		if !ok {
+			continue
+		}
+
+		if meta, err := ReadMetadata(id); err == nil {
+			status := StatusDead
+			if meta.Alive() {
+				status = StatusAlive
+			}
+			mu.Lock()
+			canvases = append(canvases, Entry{Metadata: *meta, Status: status})
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			e := Entry{Metadata: Metadata{ID: id, Socket: SocketPath(id)}, Status: StatusDead}
+			if NewClient(id).Ping() {
+				e.Status = StatusAlive
+			}
+			mu.Lock()
+			canvases = append(canvases, e)
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	sort.Slice(canvases, func(i, j int) bool { return canvases[i].ID < canvases[j].ID })
+	return canvases, nil
+}
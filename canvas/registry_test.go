@@ -0,0 +1,156 @@
+package canvas
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tempSocketDir makes DefaultSocketDir a fresh directory for the test
+func tempSocketDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := DefaultSocketDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// deadPID returns the PID of a process that has exited
+func deadPID(t *testing.T) int {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skip("cannot run true:", err)
+	}
+	return cmd.Process.Pid
+}
+
+// stateModel reports a fixed mode
+type stateModel string
+
+func (m stateModel) CanvasState() StatePayload { return StatePayload{Mode: string(m)} }
+
+// startServer starts a canvas in the socket directory
+func startServer(t *testing.T, id string, model any) *Server {
+	t.Helper()
+	s, err := NewServer(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetModel(model)
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestList(t *testing.T) {
+	dir := tempSocketDir(t)
+	touch := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// With metadata the PID decides, whether or not the socket answers.
+	startServer(t, "live", stateModel("x"))
+	touch("dead-pid.sock")
+	meta := &Metadata{ID: "dead-pid", PID: deadPID(t), Socket: SocketPath("dead-pid")}
+	if err := meta.write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without metadata the socket is pinged.
+	startServer(t, "no-meta", stateModel("x"))
+	os.Remove(MetadataPath("no-meta"))
+	touch("no-meta-gone.sock")
+
+	touch("unrelated.json")
+
+	entries, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.ID+"="+e.Status)
+	}
+	want := []string{"dead-pid=dead", "live=alive", "no-meta=alive", "no-meta-gone=dead"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	live := entries[1]
+	if live.PID != os.Getpid() || live.Socket != SocketPath("live") || live.StartedAt.IsZero() {
+		t.Errorf("live canvas has metadata %+v", live.Metadata)
+	}
+	if want := []string{CapState, CapClose, CapSubscribe, CapStats}; !slices.Equal(live.Capabilities, want) {
+		t.Errorf("capabilities = %v, want %v", live.Capabilities, want)
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	entries, err := List()
+	if err != nil || entries != nil {
+		t.Errorf("got %v, %v for a missing directory", entries, err)
+	}
+}
+
+func TestMetadataWrite(t *testing.T) {
+	dir := tempSocketDir(t)
+	meta := newMetadata("atomic", SocketPath("atomic"))
+	meta.Command = []string{strings.Repeat("x", 1<<16)}
+	if err := meta.write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Readers racing with rewrites see one complete document or the other.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			m := *meta
+			m.Capabilities = []string{strings.Repeat("c", i%100)}
+			if err := m.write(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		got, err := ReadMetadata("atomic")
+		if err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		if got.ID != "atomic" || len(got.Command) != 1 {
+			t.Fatalf("read %d: got %+v", i, got)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", f.Name())
+		}
+	}
+	data, _ := os.ReadFile(MetadataPath("atomic"))
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["pid"] != float64(os.Getpid()) {
+		t.Errorf("metadata file = %s", data)
+	}
+}
@@ -0,0 +1,270 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+)
+
+// SchemaVersion is the version of the protocol described by
+// ProtocolSchema. It changes whenever a payload changes incompatibly.
+const SchemaVersion = 1
+
+// messageSpec describes one message type of the protocol
+type messageSpec struct {
+	Type        MessageType
+	Payload     any  // zero value of the payload type, nil if none
+	Required    bool // whether the payload must be present
+	Description string
+}
+
+// messageSpecs lists every message type, in protocol order
+var messageSpecs = []messageSpec{
+	{MsgGetState, nil, false, "Query the TUI's state"},
+	{MsgGetView, ViewRequestPayload{}, false, "Query the rendered view, optionally in chunks"},
+	{MsgGetStateSchema, nil, false, "Query the JSON Schema of the custom state"},
+	{MsgSendKey, KeyPayload{}, true, "Send a key press"},
+	{MsgSendInput, InputPayload{}, true, "Send text input"},
+	{MsgClose, nil, false, "Ask the TUI to quit"},
+	{MsgSubscribe, nil, false, "Turn the connection into an event stream"},
+	{MsgAuth, AuthPayload{}, true, "Authenticate a network connection; must come first"},
+	{MsgBatch, BatchPayload{}, true, "Run several requests in order with no other request in between"},
+	{MsgStats, nil, false, "Query the server's counters and latencies"},
+
+	{MsgState, StatePayload{}, true, "State of the TUI"},
+	{MsgView, ViewPayload{}, true, "Rendered view, or its last part if chunked"},
+	{MsgViewChunk, ViewChunkPayload{}, true, "Part of a view requested in chunks; a view follows"},
+	{MsgStateSchema, StateSchemaPayload{}, true, "JSON Schema of the custom state"},
+	{MsgBatchResult, BatchResultPayload{}, true, "Responses to a batch, in request order"},
+	{MsgStatsResult, StatsPayload{}, true, "Counters and latencies since the server started"},
+	{MsgAck, ClosePayload{}, false, "Success; answers to close carry the outcome"},
+	{MsgError, ErrorPayload{}, true, "Failure"},
+
+	{MsgReady, nil, false, "The TUI is ready"},
+	{MsgUpdated, nil, false, "The view changed"},
+	{MsgSelected, nil, false, "The user selected something"},
+	{MsgCancelled, nil, false, "The user cancelled"},
+}
+
+// payloadSchema generates the inline schema of a payload type
+func payloadSchema(v any) *jsonschema.Schema {
+	// Unknown fields are ignored, so newer peers can add some.
+	r := &jsonschema.Reflector{DoNotReference: true, ExpandedStruct: true, AllowAdditionalProperties: true}
+	schema := r.Reflect(v)
+	schema.Version = ""
+	schema.ID = ""
+	return schema
+}
+
+// messageSchema returns the schema of a whole message of the given spec
+func messageSchema(spec messageSpec) map[string]any {
+	props := map[string]any{
+		"type": map[string]any{"const": spec.Type},
+	}
+	required := []string{"type"}
+	if spec.Payload != nil {
+		props["payload"] = payloadSchema(spec.Payload)
+		if spec.Required {
+			required = append(required, "payload")
+		}
+	}
+	return map[string]any{
+		"description": spec.Description,
+		"type":        "object",
+		"properties":  props,
+		"required":    required,
+	}
+}
+
+// ProtocolSchema returns the JSON Schema of every protocol message, for
+// authors of clients and servers in other languages
+func ProtocolSchema() json.RawMessage {
+	messages := make([]any, 0, len(messageSpecs))
+	for _, spec := range messageSpecs {
+		messages = append(messages, messageSchema(spec))
+	}
+	data, _ := json.Marshal(map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     fmt.Sprintf("https://github.com/AlqattanDev/opencode-canvas/schema/v%d/message.json", SchemaVersion),
+		"title":   "opencode-canvas message",
+		"version": SchemaVersion,
+		"oneOf":   messages,
+	})
+	return data
+}
+
+// MessageSchema returns the JSON Schema of one message type
+func MessageSchema(t MessageType) (json.RawMessage, bool) {
+	for _, spec := range messageSpecs {
+		if spec.Type == t {
+			data, _ := json.Marshal(messageSchema(spec))
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// PayloadError reports a payload that does not match its schema
+type PayloadError struct {
+	Field  string // JSON path of the offending field, "payload" for the whole payload
+	Reason string // e.g. "is required"
+}
+
+func (e *PayloadError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// validator is implemented by payloads with constraints beyond their types
+type validator interface {
+	validate() error
+}
+
+// validatePayload checks the payload of a request against its schema
+func validatePayload(msg *Message) error {
+	var spec *messageSpec
+	for i := range messageSpecs {
+		if messageSpecs[i].Type == msg.Type {
+			spec = &messageSpecs[i]
+			break
+		}
+	}
+	if spec == nil || spec.Payload == nil {
+		return nil
+	}
+
+	if len(msg.Payload) == 0 || bytes.Equal(msg.Payload, []byte("null")) {
+		if spec.Required {
+			return &PayloadError{Field: "payload", Reason: "is required"}
+		}
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Payload, &fields); err != nil {
+		return &PayloadError{Field: "payload", Reason: "must be an object"}
+	}
+	for _, name := range requiredFields(*spec) {
+		if _, ok := fields[name]; !ok {
+			return &PayloadError{Field: "payload." + name, Reason: "is required"}
+		}
+	}
+
+	v := reflect.New(reflect.TypeOf(spec.Payload))
+	if err := json.Unmarshal(msg.Payload, v.Interface()); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &PayloadError{
+				Field:  "payload." + typeErr.Field,
+				Reason: fmt.Sprintf("must be %s, got %s", jsonKind(typeErr.Type), typeErr.Value),
+			}
+		}
+		return &PayloadError{Field: "payload", Reason: "must be an object"}
+	}
+	if val, ok := v.Interface().(validator); ok {
+		return val.validate()
+	}
+	return nil
+}
+
+var (
+	requiredOnce sync.Once
+	required     map[MessageType][]string
+)
+
+// requiredFields returns the payload fields the schema of spec requires
+func requiredFields(spec messageSpec) []string {
+	requiredOnce.Do(func() {
+		required = make(map[MessageType][]string)
+		for _, spec := range messageSpecs {
+			if spec.Payload != nil {
+				required[spec.Type] = payloadSchema(spec.Payload).Required
+			}
+		}
+	})
+	return required[spec.Type]
+}
+
+// jsonKind names the JSON type a Go type decodes from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// JSONSchemaExtend requires a non-empty key or a rune, which the schema
+// of the fields alone cannot express. Encoders always send the key, empty
+// when only a rune is given.
+func (KeyPayload) JSONSchemaExtend(s *jsonschema.Schema) {
+	s.Required = slices.DeleteFunc(s.Required, func(name string) bool { return name == "key" })
+
+	one := uint64(1)
+	key := jsonschema.NewProperties()
+	key.Set("key", &jsonschema.Schema{MinLength: &one})
+	r := jsonschema.NewProperties()
+	r.Set("rune", &jsonschema.Schema{Minimum: json.Number("1")})
+	s.AnyOf = []*jsonschema.Schema{
+		{Properties: key, Required: []string{"key"}},
+		{Properties: r, Required: []string{"rune"}},
+	}
+}
+
+func (p *KeyPayload) validate() error {
+	if p.Key == "" && p.Rune == 0 {
+		return &PayloadError{Field: "payload.key", Reason: "is required"}
+	}
+	return nil
+}
+
+// MinChunkSize is the smallest chunk size a view may be requested in
+const MinChunkSize = 1024
+
+func (p *ViewRequestPayload) validate() error {
+	if p.ChunkSize != 0 && p.ChunkSize < MinChunkSize {
+		return &PayloadError{Field: "payload.chunk_size", Reason: fmt.Sprintf("must be 0 or at least %d", MinChunkSize)}
+	}
+	return nil
+}
+
+func (p *AuthPayload) validate() error {
+	if p.Token == "" {
+		return &PayloadError{Field: "payload.token", Reason: "is required"}
+	}
+	return nil
+}
+
+// MaxBatchSize is the most requests a batch may carry
+const MaxBatchSize = 64
+
+func (p *BatchPayload) validate() error {
+	if len(p.Messages) > MaxBatchSize {
+		return &PayloadError{Field: "payload.messages", Reason: fmt.Sprintf("must have at most %d messages", MaxBatchSize)}
+	}
+	for i, msg := range p.Messages {
+		switch msg.Type {
+		case MsgBatch, MsgSubscribe, MsgAuth:
+			return &PayloadError{
+				Field:  fmt.Sprintf("payload.messages[%d].type", i),
+				Reason: fmt.Sprintf("must not be %s inside a batch", msg.Type),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package canvas
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePayload(t *testing.T) {
+	batch := func(types ...MessageType) string {
+		msgs := make([]Message, len(types))
+		for i, t := range types {
+			msgs[i] = Message{Type: t}
+		}
+		data, _ := json.Marshal(BatchPayload{Messages: msgs})
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		t       MessageType
+		payload string
+		field   string // of the expected PayloadError, "" if valid
+	}{
+		{"no payload type", MsgGetState, `{"anything": 1}`, ""},
+		{"unknown type", "custom_thing", `[1, 2]`, ""},
+		{"key", MsgSendKey, `{"key": "enter"}`, ""},
+		{"key and rune", MsgSendKey, `{"key": "a", "rune": 97}`, ""},
+		{"rune only", MsgSendKey, `{"rune": 97}`, ""},
+		{"rune with empty key", MsgSendKey, `{"key": "", "rune": 97}`, ""},
+		{"zero rune", MsgSendKey, `{"key": "", "rune": 0}`, "payload.key"},
+		{"missing required payload", MsgSendKey, ``, "payload"},
+		{"null required payload", MsgSendKey, `null`, "payload"},
+		{"missing required field", MsgSendKey, `{}`, "payload.key"},
+		{"empty key", MsgSendKey, `{"key": ""}`, "payload.key"},
+		{"wrong field type", MsgSendKey, `{"key": 13}`, "payload.key"},
+		{"wrong rune type", MsgSendKey, `{"key": "a", "rune": "a"}`, "payload.rune"},
+		{"non-object payload", MsgSendInput, `"hello"`, "payload"},
+		{"array payload", MsgSendInput, `["hello"]`, "payload"},
+		{"input", MsgSendInput, `{"text": "hello"}`, ""},
+		{"unknown fields ignored", MsgSendInput, `{"text": "hello", "extra": true}`, ""},
+		{"optional payload absent", MsgGetView, ``, ""},
+		{"chunk size", MsgGetView, `{"chunk_size": 4096}`, ""},
+		{"chunk size too small", MsgGetView, `{"chunk_size": 10}`, "payload.chunk_size"},
+		{"empty token", MsgAuth, `{"token": ""}`, "payload.token"},
+		{"batch", MsgBatch, batch(MsgGetState, MsgGetView), ""},
+		{"nested batch", MsgBatch, batch(MsgGetState, MsgBatch), "payload.messages[1].type"},
+		{"subscribe in batch", MsgBatch, batch(MsgSubscribe), "payload.messages[0].type"},
+		{"oversized batch", MsgBatch, batch(make([]MessageType, MaxBatchSize+1)...), "payload.messages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{Type: tt.t}
+			if tt.payload != "" {
+				msg.Payload = json.RawMessage(tt.payload)
+			}
+			err := validatePayload(msg)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var perr *PayloadError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %v, want a PayloadError for %s", err, tt.field)
+			}
+			if perr.Field != tt.field {
+				t.Errorf("field = %q, want %q (%v)", perr.Field, tt.field, err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.field) {
+				t.Errorf("error %q does not name the field", err)
+			}
+		})
+	}
+}
@@ -2,12 +2,19 @@ package canvas
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 )
 
 // StateProvider is implemented by TUI models to expose their state
@@ -34,17 +41,43 @@ type InputHandler interface {
 	HandleCanvasInput(text string) error
 }
 
+// CloseHandler is implemented by TUI models that decide whether to close
+type CloseHandler interface {
+	// HandleCanvasClose is called for a close request. Returning an error
+	// vetoes it, e.g. while there are unsaved changes.
+	HandleCanvasClose() error
+}
+
 // Server handles IPC communication for a TUI
 type Server struct {
 	id       string
 	socket   string
 	listener net.Listener
-	
-	mu       sync.RWMutex
-	model    any // The TUI model
-	onClose  func()
-	
+	remotes  []*remoteListener // network listeners from WithListen
+	metricsL net.Listener      // Prometheus endpoint from WithMetricsAddr
+
+	mu           sync.RWMutex
+	model        any // The TUI model
+	onClose      func()
+	onConnect    func(net.Conn)
+	onDisconnect func(net.Conn)
+	subs         map[chan *Message]struct{}  // event streams of subscribed clients
+	meta         *Metadata                   // registry entry, guarded by mu
+	conns        map[net.Conn]struct{}       // open connections, guarded by mu
+	closing      bool                        // set once shutdown begins, guarded by mu
+	handlers     map[MessageType]HandlerFunc // see Handle, guarded by mu
+	middleware   []Middleware                // see Use, guarded by mu
+	chain        HandlerFunc                 // middleware around dispatch, guarded by mu
+
+	inflight sync.WaitGroup // requests being handled
+	batchMu  sync.RWMutex   // held exclusively while a batch runs
+	stopOnce sync.Once
 	done     chan struct{}
+
+	opts    *serverOptions
+	log     *slog.Logger // opts.logger with the canvas ID
+	audit   *auditLog    // see WithAuditLog
+	metrics *metrics
 }
 
 // DefaultSocketDir returns the default directory for canvas sockets
@@ -54,32 +87,177 @@ func DefaultSocketDir() string {
 
 // SocketPath returns the socket path for a canvas ID
 func SocketPath(id string) string {
-	return filepath.Join(DefaultSocketDir(), fmt.Sprintf("%s.sock", id))
+	return socketPathIn(DefaultSocketDir(), id)
+}
+
+func socketPathIn(dir, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.sock", id))
 }
 
+// ErrIDInUse is returned by NewServer when a running canvas already owns
+// the requested ID
+var ErrIDInUse = errors.New("canvas ID already in use")
+
+// maxIDSuffix bounds the IDs tried by WithAutoSuffix
+const maxIDSuffix = 100
+
 // NewServer creates a new IPC server for the given canvas ID
-func NewServer(id string) (*Server, error) {
-	socketDir := DefaultSocketDir()
-	if err := os.MkdirAll(socketDir, 0755); err != nil {
+func NewServer(id string, opts ...ServerOption) (*Server, error) {
+	o := newServerOptions(opts)
+
+	if err := os.MkdirAll(o.socketDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create socket dir: %w", err)
 	}
-	
-	socketPath := SocketPath(id)
-	
-	// Remove existing socket if present
-	os.Remove(socketPath)
-	
+
+	audit, err := openAuditLog(o.auditLog, o.redactor)
+	if err != nil {
+		return nil, err
+	}
+	var metricsL net.Listener
+	started := false
+	defer func() {
+		if !started {
+			audit.close()
+			if metricsL != nil {
+				metricsL.Close()
+			}
+		}
+	}()
+
+	listener, chosen, err := listenCanvas(o.socketDir, id, o.autoSuffix)
+	if err != nil {
+		return nil, err
+	}
+	socketPath := socketPathIn(o.socketDir, chosen)
+
+	if o.socketMode != 0 {
+		if err := os.Chmod(socketPath, o.socketMode); err != nil {
+			listener.Close()
+			os.Remove(socketPath)
+			return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+		}
+	}
+
+	remotes, err := listenRemote(o)
+	if err != nil {
+		listener.Close()
+		os.Remove(socketPath)
+		return nil, err
+	}
+
+	if o.metricsAddr != "" {
+		metricsL, err = net.Listen("tcp", o.metricsAddr)
+		if err != nil {
+			listener.Close()
+			for _, l := range remotes {
+				l.Close()
+			}
+			os.Remove(socketPath)
+			return nil, fmt.Errorf("failed to listen for metrics on %s: %w", o.metricsAddr, err)
+		}
+	}
+
+	handlers := make(map[MessageType]HandlerFunc, len(builtinHandlers)+len(o.handlers))
+	for t, h := range builtinHandlers {
+		handlers[t] = h
+	}
+	for t, h := range o.handlers {
+		if h == nil {
+			delete(handlers, t)
+		} else {
+			handlers[t] = h
+		}
+	}
+
+	meta := newMetadata(chosen, socketPath)
+	if chosen != id {
+		meta.RequestedID = id
+	}
+	for _, l := range remotes {
+		meta.Addrs = append(meta.Addrs, l.url(chosen))
+	}
+	meta.Capabilities = append(capabilitiesOf(nil), customTypes(handlers)...)
+	if audit != nil {
+		meta.AuditLog = audit.path
+	}
+	if metricsL != nil {
+		meta.MetricsURL = "http://" + metricsL.Addr().String() + "/metrics"
+	}
+	if err := meta.write(); err != nil {
+		listener.Close()
+		for _, l := range remotes {
+			l.Close()
+		}
+		os.Remove(socketPath)
+		return nil, fmt.Errorf("failed to write canvas metadata: %w", err)
+	}
+
+	s := &Server{
+		id:         chosen,
+		socket:     socketPath,
+		listener:   listener,
+		remotes:    remotes,
+		metricsL:   metricsL,
+		subs:       make(map[chan *Message]struct{}),
+		meta:       meta,
+		conns:      make(map[net.Conn]struct{}),
+		handlers:   handlers,
+		middleware: append([]Middleware(nil), o.middleware...),
+		done:       make(chan struct{}),
+		opts:       o,
+		log:        o.logger.With("id", chosen),
+		audit:      audit,
+		metrics:    newMetrics(),
+	}
+	s.buildChain()
+	started = true
+
+	// Clean up after crashed canvases, without holding up the app.
+	go gcDir(o.socketDir, chosen)
+	return s, nil
+}
+
+// listenCanvas claims the socket of id in dir, or with autoSuffix the
+// first free suffixed variant of it, and returns the listener and the
+// claimed ID
+func listenCanvas(dir, id string, autoSuffix bool) (net.Listener, string, error) {
+	for n := 1; n <= maxIDSuffix; n++ {
+		candidate := id
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", id, n)
+		}
+
+		listener, err := claimSocket(socketPathIn(dir, candidate))
+		if err == nil {
+			return listener, candidate, nil
+		}
+		if !autoSuffix || !errors.Is(err, ErrIDInUse) {
+			return nil, "", err
+		}
+	}
+	return nil, "", fmt.Errorf("no free ID for canvas '%s': %w", id, ErrIDInUse)
+}
+
+// claimSocket listens on a canvas socket, taking it over only if its
+// previous owner is gone
+func claimSocket(socketPath string) (net.Listener, error) {
+	id := strings.TrimSuffix(filepath.Base(socketPath), ".sock")
+	if _, err := os.Stat(socketPath); err == nil {
+		if !staleSocket(socketPath) {
+			return nil, fmt.Errorf("canvas '%s': %w", id, ErrIDInUse)
+		}
+		os.Remove(socketPath)
+	}
+
 	listener, err := net.Listen("unix", socketPath)
 	if err != nil {
+		// Another server claimed the ID since the check above.
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return nil, fmt.Errorf("canvas '%s': %w", id, ErrIDInUse)
+		}
 		return nil, fmt.Errorf("failed to listen on socket: %w", err)
 	}
-	
-	return &Server{
-		id:       id,
-		socket:   socketPath,
-		listener: listener,
-		done:     make(chan struct{}),
-	}, nil
+	return listener, nil
 }
 
 // SetModel sets the TUI model for state queries
@@ -87,6 +265,16 @@ func (s *Server) SetModel(model any) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.model = model
+
+	// Advertise what the new model supports.
+	s.writeCapabilities()
+}
+
+// Metadata returns the registry entry written for this server
+func (s *Server) Metadata() Metadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *s.meta
 }
 
 // OnClose sets a callback for when a close message is received
@@ -96,16 +284,97 @@ func (s *Server) OnClose(fn func()) {
 	s.onClose = fn
 }
 
+// OnConnect sets a callback for when a client connects
+func (s *Server) OnConnect(fn func(conn net.Conn)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onConnect = fn
+}
+
+// OnDisconnect sets a callback for when a client connection ends
+func (s *Server) OnDisconnect(fn func(conn net.Conn)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDisconnect = fn
+}
+
 // Start begins accepting connections
 func (s *Server) Start() {
-	go s.acceptLoop()
+	go s.acceptLoop(s.listener, false)
+	for _, l := range s.remotes {
+		if l.scheme == "ws" || l.scheme == "wss" {
+			go s.serveWebSocket(l)
+		} else {
+			go s.acceptLoop(l, true)
+		}
+	}
+	if s.metricsL != nil {
+		go s.serveMetrics(s.metricsL)
+	}
 }
 
-// Stop closes the server
+// Stop closes the server and all connections immediately and removes its
+// socket and metadata. It is safe to call more than once.
 func (s *Server) Stop() {
+	s.stopAccepting()
+	s.stopOnce.Do(func() {
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+
+		os.Remove(s.socket)
+		os.Remove(metadataFile(s.socket))
+
+		// Requests still running record themselves when they finish. Stop
+		// must not wait for them, as it may be called from one.
+		go func() {
+			s.inflight.Wait()
+			s.audit.close()
+		}()
+	})
+}
+
+// Shutdown stops accepting connections and requests, waits for the
+// requests being handled to finish, then stops the server. If ctx ends
+// first the remaining connections are closed and ctx's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopAccepting()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	s.Stop()
+	return err
+}
+
+// stopAccepting closes the listener and ends subscriptions; requests that
+// are already being handled keep running
+func (s *Server) stopAccepting() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return
+	}
+	s.closing = true
 	close(s.done)
 	s.listener.Close()
-	os.Remove(s.socket)
+	for _, l := range s.remotes {
+		l.Close()
+	}
+	if s.metricsL != nil {
+		s.metricsL.Close()
+	}
 }
 
 // SocketPath returns the path to the Unix socket
@@ -113,133 +382,333 @@ func (s *Server) SocketPath() string {
 	return s.socket
 }
 
-// ID returns the canvas ID
+// Addrs returns the network addresses the canvas listens on, in the form
+// accepted by Dial
+func (s *Server) Addrs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.meta.Addrs...)
+}
+
+// ID returns the canvas ID, which differs from the requested one if
+// WithAutoSuffix picked a free variant
 func (s *Server) ID() string {
 	return s.id
 }
 
-// SendEvent sends an async event to any connected client
+// SendEvent sends an async event to every subscribed client. Events are
+// dropped for subscribers that are not keeping up.
 func (s *Server) SendEvent(msgType MessageType, payload any) error {
 	msg, err := NewMessage(msgType, payload)
 	if err != nil {
 		return err
 	}
-	// For now, events are logged but not broadcast
-	// (would need connection tracking for proper broadcast)
-	_ = msg
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for events := range s.subs {
+		select {
+		case events <- msg:
+		default:
+			s.log.Debug("canvas event dropped for a slow subscriber", "type", msgType)
+		}
+	}
 	return nil
 }
 
-func (s *Server) acceptLoop() {
+// maxAcceptDelay caps the backoff after failed accepts
+const maxAcceptDelay = time.Second
+
+// acceptLoop serves a listener; remote connections must authenticate if
+// a token is set
+func (s *Server) acceptLoop(l net.Listener, remote bool) {
+	var delay time.Duration // backoff while accepts fail
 	for {
 		select {
 		case <-s.done:
 			return
 		default:
 		}
-		
-		conn, err := s.listener.Accept()
+
+		conn, err := l.Accept()
 		if err != nil {
 			select {
 			case <-s.done:
 				return
 			default:
-				continue
 			}
+			if errors.Is(err, net.ErrClosed) {
+				s.reportError(fmt.Errorf("listener closed unexpectedly: %w", err))
+				return
+			}
+
+			// Errors such as running out of file descriptors persist for
+			// a while; retrying at once would spin.
+			delay = min(max(2*delay, 5*time.Millisecond), maxAcceptDelay)
+			s.reportError(fmt.Errorf("failed to accept connection, retrying in %s: %w", delay, err))
+			select {
+			case <-time.After(delay):
+			case <-s.done:
+				return
+			}
+			continue
 		}
-		
-		go s.handleConnection(conn)
+		delay = 0
+
+		go s.handleConnection(conn, remote)
 	}
 }
 
-func (s *Server) handleConnection(conn net.Conn) {
+func (s *Server) handleConnection(conn net.Conn, remote bool) {
 	defer conn.Close()
-	
-	reader := bufio.NewReader(conn)
-	encoder := json.NewEncoder(conn)
-	
+
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return
+	}
+	if limit := s.opts.maxConnections; limit > 0 && len(s.conns) >= limit {
+		s.mu.Unlock()
+		s.log.Warn("canvas connection refused", "max_connections", limit)
+		s.sendError(json.NewEncoder(conn), CodeTooManyConnections,
+			fmt.Sprintf("canvas accepts at most %d connections", limit))
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.metrics.connections.Add(1)
+	open := len(s.conns)
+	onConnect, onDisconnect := s.onConnect, s.onDisconnect
+	s.mu.Unlock()
+
+	log := s.log
+	if remote {
+		log = log.With("remote", conn.RemoteAddr().String())
+	}
+	log.Debug("canvas client connected", "conns", open)
+	connected := time.Now()
+	defer func() {
+		log.Debug("canvas client disconnected", "duration", time.Since(connected))
+	}()
+
+	if onConnect != nil {
+		onConnect(conn)
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		if onDisconnect != nil {
+			onDisconnect(conn)
+		}
+	}()
+
+	reader := bufio.NewReader(countingReader{conn, &s.metrics.bytesIn})
+	encoder := json.NewEncoder(countingWriter{conn, &s.metrics.bytesOut})
+
+	if remote && s.opts.token != "" && !s.authenticate(conn, reader, encoder) {
+		return
+	}
+
+	rpc := false // whether the client speaks JSON-RPC, see jsonrpc.go
 	for {
-		line, err := reader.ReadBytes('\n')
+		if s.opts.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.opts.idleTimeout))
+		}
+		line, err := readMessage(reader, s.opts.maxMessageSize)
+		if errors.Is(err, errMessageTooLarge) {
+			limit := s.opts.maxMessageSize
+			log.Warn("canvas message too large", "max_message_size", limit)
+			if s.opts.writeTimeout > 0 {
+				conn.SetWriteDeadline(time.Now().Add(s.opts.writeTimeout))
+			}
+			s.sendError(encoder, CodeMessageTooLarge, fmt.Sprintf("messages are limited to %d bytes", limit))
+			return
+		}
 		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				log.Debug("canvas client idle, disconnecting", "idle_timeout", s.opts.idleTimeout)
+			}
+			return
+		}
+		if s.opts.writeTimeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(s.opts.writeTimeout))
+		}
+		if !s.beginRequest() {
 			return
 		}
-		
+
+		if isJSONRPC(line) {
+			rpc = true
+			subscribe, ack, err := s.handleRPC(conn, encoder, line)
+			s.inflight.Done()
+			if err != nil {
+				return
+			}
+			if subscribe {
+				s.serveSubscription(conn, encoder, ack, rpcEvent)
+				return
+			}
+			continue
+		}
+
 		var msg Message
 		if err := json.Unmarshal(line, &msg); err != nil {
-			s.sendError(encoder, "parse_error", err.Error())
+			// Answer in the dialect the client has been speaking.
+			log.Debug("canvas request unparseable", "err", err)
+			if rpc {
+				err = s.send(encoder, rpcFailure(rpcNull, rpcParseError, CodeParseError, err.Error()))
+			} else {
+				err = s.sendError(encoder, CodeParseError, err.Error())
+			}
+			s.inflight.Done()
+			if err != nil {
+				return
+			}
 			continue
 		}
-		
-		s.handleMessage(&msg, encoder)
+
+		if msg.Type == MsgSubscribe {
+			// A subscription is not a request; it ends with the server.
+			s.inflight.Done()
+			ack, _ := NewMessage(MsgAck, nil)
+			s.serveSubscription(conn, encoder, ack, func(event *Message) any { return event })
+			return
+		}
+
+		err = s.writeResponse(conn, encoder, &msg, s.respond(conn, &msg))
+		s.inflight.Done()
+		if err != nil {
+			return
+		}
 	}
 }
 
-func (s *Server) handleMessage(msg *Message, enc *json.Encoder) {
-	s.mu.RLock()
-	model := s.model
-	onClose := s.onClose
-	s.mu.RUnlock()
-	
-	switch msg.Type {
-	case MsgGetState:
-		if sp, ok := model.(StateProvider); ok {
-			state := sp.CanvasState()
-			resp, _ := NewMessage(MsgState, state)
-			enc.Encode(resp)
-		} else {
-			s.sendError(enc, "not_supported", "model does not implement StateProvider")
-		}
-		
-	case MsgGetView:
-		if vp, ok := model.(ViewProvider); ok {
-			view := ViewPayload{Content: vp.CanvasView(), ANSI: true}
-			resp, _ := NewMessage(MsgView, view)
-			enc.Encode(resp)
-		} else {
-			s.sendError(enc, "not_supported", "model does not implement ViewProvider")
-		}
-		
-	case MsgSendKey:
-		if kh, ok := model.(KeyHandler); ok {
-			var payload KeyPayload
-			msg.ParsePayload(&payload)
-			if err := kh.HandleCanvasKey(payload.Key, payload.Rune); err != nil {
-				s.sendError(enc, "key_error", err.Error())
-			} else {
-				resp, _ := NewMessage(MsgAck, nil)
-				enc.Encode(resp)
+// beginRequest registers an in-flight request unless the server is
+// shutting down
+func (s *Server) beginRequest() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// serveSubscription sends ack, if any, then streams events to the
+// connection in the form returned by format until the client disconnects
+// or the server stops
+func (s *Server) serveSubscription(conn net.Conn, enc *json.Encoder, ack any, format func(*Message) any) {
+	events := make(chan *Message, 16)
+	s.mu.Lock()
+	s.subs[events] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, events)
+		s.mu.Unlock()
+	}()
+
+	// Subscribers stay quiet, so only writes are bounded.
+	conn.SetReadDeadline(time.Time{})
+
+	if ack != nil {
+		if err := s.send(enc, ack); err != nil {
+			return
+		}
+	}
+	s.log.Debug("canvas client subscribed")
+
+	// Subscribers only listen, so the read side ends when they hang up.
+	gone := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+
+	for {
+		select {
+		case msg := <-events:
+			if s.opts.writeTimeout > 0 {
+				conn.SetWriteDeadline(time.Now().Add(s.opts.writeTimeout))
 			}
-		} else {
-			s.sendError(enc, "not_supported", "model does not implement KeyHandler")
-		}
-		
-	case MsgSendInput:
-		if ih, ok := model.(InputHandler); ok {
-			var payload InputPayload
-			msg.ParsePayload(&payload)
-			if err := ih.HandleCanvasInput(payload.Text); err != nil {
-				s.sendError(enc, "input_error", err.Error())
-			} else {
-				resp, _ := NewMessage(MsgAck, nil)
-				enc.Encode(resp)
+			if err := enc.Encode(format(msg)); err != nil {
+				s.log.Debug("canvas subscriber dropped", "err", err)
+				return
 			}
-		} else {
-			s.sendError(enc, "not_supported", "model does not implement InputHandler")
-		}
-		
-	case MsgClose:
-		if onClose != nil {
-			onClose()
+		case <-gone:
+			return
+		case <-s.done:
+			return
 		}
-		resp, _ := NewMessage(MsgAck, nil)
-		enc.Encode(resp)
-		
-	default:
-		s.sendError(enc, "unknown_type", fmt.Sprintf("unknown message type: %s", msg.Type))
 	}
 }
 
-func (s *Server) sendError(enc *json.Encoder, code, message string) {
+// respond passes a request through the middleware to its handler and
+// returns the response
+func (s *Server) respond(conn net.Conn, msg *Message) *Message {
+	s.mu.RLock()
+	req := &Request{Message: *msg, Model: s.model, Conn: conn, server: s}
+	chain := s.chain
+	s.mu.RUnlock()
+
+	// No other request may run between the parts of a batch.
+	if msg.Type == MsgBatch {
+		s.batchMu.Lock()
+		defer s.batchMu.Unlock()
+	} else {
+		s.batchMu.RLock()
+		defer s.batchMu.RUnlock()
+	}
+
+	start := time.Now()
+	resp := chain(req)
+	s.logRequest(msg.Type, resp, time.Since(start))
+	return resp
+}
+
+// logRequest records a handled request; failures caused by the app rather
+// than the client are logged as errors
+func (s *Server) logRequest(t MessageType, resp *Message, elapsed time.Duration) {
+	if resp.Type != MsgError {
+		s.log.Debug("canvas request handled", "type", t, "duration", elapsed)
+		return
+	}
+
+	var payload ErrorPayload
+	resp.ParsePayload(&payload)
+	level := slog.LevelInfo
+	if payload.Code == CodeInternalError {
+		level = slog.LevelError
+	}
+	s.log.Log(context.Background(), level, "canvas request failed",
+		"type", t, "duration", elapsed, "code", payload.Code, "err", payload.Message)
+}
+
+// reportError logs an error that has no caller to return to and passes it
+// to the error handler
+func (s *Server) reportError(err error) {
+	s.log.Error("canvas server error", "err", err)
+	if s.opts.onError != nil {
+		s.opts.onError(err)
+	}
+}
+
+// send writes a response, logging a failure, after which the connection
+// is unusable
+func (s *Server) send(enc *json.Encoder, v any) error {
+	err := enc.Encode(v)
+	if err != nil {
+		s.log.Warn("canvas failed to send response", "err", err)
+	}
+	return err
+}
+
+func (s *Server) sendError(enc *json.Encoder, code, message string) error {
+	return s.send(enc, errorMessage(code, message))
+}
+
+func errorMessage(code, message string) *Message {
 	resp, _ := NewMessage(MsgError, ErrorPayload{Code: code, Message: message})
-	enc.Encode(resp)
+	return resp
 }
@@ -0,0 +1,251 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// holdSocket listens on the socket of id until the test ends, standing in
+// for a running canvas
+func holdSocket(t *testing.T, id string) {
+	t.Helper()
+	l, err := net.Listen("unix", SocketPath(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+}
+
+func TestNewServerIDInUse(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "dup", stateModel("x"))
+
+	_, err := NewServer("dup")
+	if !errors.Is(err, ErrIDInUse) {
+		t.Fatalf("got %v, want ErrIDInUse", err)
+	}
+	if _, err := ReadMetadata("dup"); err != nil {
+		t.Errorf("metadata of the running canvas: %v", err)
+	}
+}
+
+func TestNewServerStaleSocket(t *testing.T) {
+	tempSocketDir(t)
+	l, err := net.Listen("unix", SocketPath("stale"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	s := startServer(t, "stale", stateModel("x"))
+	if s.ID() != "stale" {
+		t.Errorf("ID = %q, want stale", s.ID())
+	}
+	if _, err := NewClient("stale").GetState(); err != nil {
+		t.Errorf("reclaimed socket does not answer: %v", err)
+	}
+}
+
+func TestAutoSuffix(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "app", stateModel("x"))
+
+	for _, want := range []string{"app-2", "app-3"} {
+		s, err := NewServer("app", WithAutoSuffix())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(s.Stop)
+		if s.ID() != want {
+			t.Errorf("ID = %q, want %q", s.ID(), want)
+		}
+		if meta := s.Metadata(); meta.ID != want || meta.RequestedID != "app" {
+			t.Errorf("metadata has ID %q, requested ID %q", meta.ID, meta.RequestedID)
+		}
+	}
+
+	for n := 4; n < maxIDSuffix; n++ {
+		holdSocket(t, fmt.Sprintf("app-%d", n))
+	}
+	s, err := NewServer("app", WithAutoSuffix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	if want := fmt.Sprintf("app-%d", maxIDSuffix); s.ID() != want {
+		t.Errorf("ID = %q, want %q", s.ID(), want)
+	}
+
+	if _, err := NewServer("app", WithAutoSuffix()); !errors.Is(err, ErrIDInUse) {
+		t.Errorf("got %v once all %d IDs are taken, want ErrIDInUse", err, maxIDSuffix)
+	}
+}
+
+// closeModel answers close requests with err
+type closeModel struct{ err error }
+
+func (m closeModel) HandleCanvasClose() error { return m.err }
+
+func TestClose(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   any
+		onClose bool
+		want    ClosePayload
+	}{
+		{"accepted", closeModel{}, false, ClosePayload{Exited: true}},
+		{"vetoed", closeModel{errors.New("unsaved changes")}, true, ClosePayload{Reason: "unsaved changes"}},
+		{"callback only", stateModel("x"), true, ClosePayload{Exited: true}},
+		{"no handler", stateModel("x"), false, ClosePayload{Exited: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempSocketDir(t)
+			s := startServer(t, "close", tt.model)
+			called := false
+			if tt.onClose {
+				s.OnClose(func() { called = true })
+			}
+
+			result, err := NewClient("close").RequestClose()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *result != tt.want {
+				t.Errorf("got %+v, want %+v", *result, tt.want)
+			}
+			if wantCalled := tt.onClose && tt.want.Exited; called != wantCalled {
+				t.Errorf("OnClose called = %v, want %v", called, wantCalled)
+			}
+		})
+	}
+}
+
+func TestCloseBareAck(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "plain", stateModel("x"))
+
+	conn, err := net.Dial("unix", SocketPath("plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte(`{"type":"close"}` + "\n"))
+	var resp Message
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Type != MsgAck || resp.Payload != nil {
+		t.Errorf("got %s %s, want a bare ack", resp.Type, resp.Payload)
+	}
+}
+
+func TestClientCloseRefused(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "veto", closeModel{errors.New("unsaved changes")})
+
+	err := NewClient("veto").Close()
+	if !errors.Is(err, ErrCloseRefused) || !strings.Contains(err.Error(), "unsaved changes") {
+		t.Errorf("got %v, want ErrCloseRefused with the reason", err)
+	}
+
+	startServer(t, "accept", closeModel{})
+	if err := NewClient("accept").Close(); err != nil {
+		t.Errorf("accepted close returned %v", err)
+	}
+}
+
+// slowModel holds state requests until release is closed
+type slowModel struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m slowModel) CanvasState() StatePayload {
+	m.started <- struct{}{}
+	<-m.release
+	return StatePayload{Mode: "slow"}
+}
+
+// assertRemoved fails unless the socket and metadata of s are gone
+func assertRemoved(t *testing.T, s *Server) {
+	t.Helper()
+	for _, path := range []string{s.SocketPath(), MetadataPath(s.ID())} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", filepath.Base(path))
+		}
+	}
+}
+
+func TestStop(t *testing.T) {
+	tempSocketDir(t)
+	s := startServer(t, "stop", stateModel("x"))
+	s.Stop()
+	s.Stop()
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown after Stop: %v", err)
+	}
+	assertRemoved(t, s)
+	if _, err := NewClient("stop").GetState(); err == nil {
+		t.Error("stopped server still answers")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	tempSocketDir(t)
+	m := slowModel{started: make(chan struct{}), release: make(chan struct{})}
+	s := startServer(t, "shutdown", m)
+
+	replies := make(chan error, 1)
+	go func() {
+		state, err := NewClient("shutdown").GetState()
+		if err == nil && state.Mode != "slow" {
+			err = fmt.Errorf("got mode %q", state.Mode)
+		}
+		replies <- err
+	}()
+	<-m.started
+
+	shut := make(chan error, 1)
+	go func() { shut <- s.Shutdown(context.Background()) }()
+	select {
+	case err := <-shut:
+		t.Fatalf("Shutdown returned %v with a request in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(m.release)
+	if err := <-shut; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+	if err := <-replies; err != nil {
+		t.Errorf("in-flight request: %v", err)
+	}
+	assertRemoved(t, s)
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	tempSocketDir(t)
+	m := slowModel{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(m.release)
+	s := startServer(t, "deadline", m)
+
+	go NewClient("deadline").GetState()
+	<-m.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	assertRemoved(t, s)
+}
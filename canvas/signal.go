@@ -0,0 +1,33 @@
+package canvas
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals stops the server when the process receives one of sigs
+// (SIGINT, SIGTERM and SIGHUP by default) so its socket is not left behind.
+// The signal is then raised again, so the process still terminates unless
+// something else handles it.
+func (s *Server) HandleSignals(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case sig := <-ch:
+			s.log.Info("canvas stopping on signal", "signal", sig.String())
+			s.Stop()
+			signal.Stop(ch)
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(sig)
+			}
+		case <-s.done:
+		}
+	}()
+}
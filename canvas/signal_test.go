@@ -0,0 +1,32 @@
+package canvas
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	tempSocketDir(t)
+	s := startServer(t, "signals", stateModel("x"))
+
+	// Catch SIGUSR1 here too, so that the signal raised again after
+	// stopping does not end the test binary.
+	caught := make(chan os.Signal, 2)
+	signal.Notify(caught, syscall.SIGUSR1)
+	defer signal.Stop(caught)
+
+	s.HandleSignals(syscall.SIGUSR1)
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-caught:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d signals, want the original and the raised one", i)
+		}
+	}
+	assertRemoved(t, s)
+}
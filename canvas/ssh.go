@@ -0,0 +1,146 @@
+package canvas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteSocketDir is where canvases on remote hosts are looked for. It
+// matches DefaultSocketDir on hosts whose temp directory is /tmp.
+const RemoteSocketDir = "/tmp/opencode-canvas"
+
+// remoteSocketPath returns the socket on the remote host for an ID or
+// socket path taken from an ssh:// address
+func remoteSocketPath(target string) string {
+	if strings.HasSuffix(target, ".sock") {
+		return "/" + strings.TrimPrefix(target, "/")
+	}
+	return path.Join(RemoteSocketDir, target+".sock")
+}
+
+// ShellQuote quotes s as a single word for sh
+func ShellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// WithSSHCommand replaces the ssh invocation used for ssh:// addresses.
+// The command runs through sh with {host} and {socket} substituted, each
+// quoted as a single word, and must connect its stdin and stdout to the canvas socket, e.g.
+// "ssh {host} opencode-canvas pipe {socket}".
+func WithSSHCommand(command string) ClientOption {
+	return func(c *Client) {
+		c.sshCmd = command
+	}
+}
+
+// dialSSH starts an ssh process whose stdio is forwarded to the remote
+// canvas socket
+func (c *Client) dialSSH() (net.Conn, error) {
+	var cmd *exec.Cmd
+	if c.sshCmd != "" {
+		script := strings.NewReplacer("{host}", ShellQuote(c.sshHost), "{socket}", ShellQuote(c.socket)).Replace(c.sshCmd)
+		cmd = exec.Command("sh", "-c", script)
+	} else {
+		args := []string{"-W", c.socket}
+		if c.sshPort != "" {
+			args = append(args, "-p", c.sshPort)
+		}
+		cmd = exec.Command("ssh", append(args, "--", c.sshHost)...)
+	}
+
+	conn := &cmdConn{cmd: cmd}
+	var err error
+	if conn.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if conn.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	cmd.Stderr = &conn.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ssh: %w", err)
+	}
+	return conn, nil
+}
+
+// cmdConn is a net.Conn over the stdio of a forwarding process. Deadlines
+// are enforced by killing the process.
+type cmdConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	closeOnce sync.Once
+	waitOnce  sync.Once
+}
+
+// wait reaps the process; the stderr buffer is complete afterwards
+func (c *cmdConn) wait() {
+	c.waitOnce.Do(func() { c.cmd.Wait() })
+}
+
+func (c *cmdConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+		c.wait()
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			return n, errors.New(msg)
+		}
+	}
+	return n, err
+}
+
+func (c *cmdConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *cmdConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		if c.timer != nil {
+			c.timer.Stop()
+		}
+		c.mu.Unlock()
+		c.stdin.Close()
+		c.cmd.Process.Kill()
+		go c.wait()
+	})
+	return nil
+}
+
+func (c *cmdConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if !t.IsZero() {
+		c.timer = time.AfterFunc(time.Until(t), func() { c.Close() })
+	}
+	return nil
+}
+
+func (c *cmdConn) SetReadDeadline(t time.Time) error  { return c.SetDeadline(t) }
+func (c *cmdConn) SetWriteDeadline(t time.Time) error { return c.SetDeadline(t) }
+func (c *cmdConn) LocalAddr() net.Addr                { return cmdAddr{} }
+func (c *cmdConn) RemoteAddr() net.Addr               { return cmdAddr{} }
+
+type cmdAddr struct{}
+
+func (cmdAddr) Network() string { return "ssh" }
+func (cmdAddr) String() string  { return "ssh" }
@@ -0,0 +1,81 @@
+package canvas
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"me@host.example:22", "me@host.example:22"},
+		{"/tmp/opencode-canvas/my-app.sock", "/tmp/opencode-canvas/my-app.sock"},
+		{"", "''"},
+		{"two words", "'two words'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf ~)", "'$(rm -rf ~)'"},
+		{"a;b`c`", "'a;b`c`'"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		out, err := exec.Command("sh", "-c", "printf %s "+ShellQuote(tt.in)).Output()
+		if err != nil || string(out) != tt.in {
+			t.Errorf("sh got %q (%v) for %q", out, err, tt.in)
+		}
+	}
+}
+
+// stateScript answers one get_state with the "ssh" mode
+const stateScript = `printf '{"type":"state","payload":{"mode":"ssh"}}\n'; cat >/dev/null`
+
+func TestSSHCommand(t *testing.T) {
+	args := filepath.Join(t.TempDir(), "args")
+	c, err := Dial("ssh://me@host/a;b'c $(x).sock",
+		WithSSHCommand(`printf '%s\n' {host} {socket} >`+ShellQuote(args)+`; `+stateScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := c.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Mode != "ssh" {
+		t.Errorf("mode = %q", state.Mode)
+	}
+
+	data, _ := os.ReadFile(args)
+	if want := "me@host\n/a;b'c $(x).sock\n"; string(data) != want {
+		t.Errorf("command got %q, want %q", data, want)
+	}
+}
+
+func TestSSHDefaultCommand(t *testing.T) {
+	// A fake ssh records its arguments.
+	bin := t.TempDir()
+	args := filepath.Join(bin, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" >" + ShellQuote(args) + "\n" + stateScript + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c, err := Dial("ssh://-oProxyCommand=x:2222/my-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetState(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(args)
+	want := []string{"-W", RemoteSocketDir + "/my-app.sock", "-p", "2222", "--", "-oProxyCommand=x"}
+	if got := strings.Fields(string(data)); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("ssh got %q, want %q", got, want)
+	}
+}
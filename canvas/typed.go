@@ -0,0 +1,150 @@
+package canvas
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// TypedStateProvider is implemented by TUI models that expose their state
+// as their own type instead of a map. The server embeds the value under
+// "custom" (next to the standard fields of CanvasState, if the model also
+// implements StateProvider) and publishes a JSON Schema generated from T.
+// A T that does not encode to a JSON object, such as an int or a slice,
+// is embedded as {"value": ...}.
+type TypedStateProvider[T any] interface {
+	// CanvasTypedState returns the current state for IPC queries
+	CanvasTypedState() T
+}
+
+// typedStateMethod is the method of TypedStateProvider. The server cannot
+// assert the generic interface without knowing T, so it looks the method
+// up by reflection.
+const typedStateMethod = "CanvasTypedState"
+
+// wrapper is implemented by adapters such as BubbleTeaAdapter that forward
+// to the model they wrap
+type wrapper interface {
+	canvasModel() any
+}
+
+// typedState returns the model's CanvasTypedState method, if it has one
+func typedState(model any) (reflect.Value, bool) {
+	if model == nil {
+		return reflect.Value{}, false
+	}
+	m := reflect.ValueOf(model).MethodByName(typedStateMethod)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	return m, true
+}
+
+// modelState returns the state of a model implementing StateProvider,
+// TypedStateProvider or both. ok is false if it implements neither.
+func modelState(model any) (state StatePayload, ok bool, err error) {
+	sp, isProvider := model.(StateProvider)
+	typed, isTyped := typedState(model)
+	if !isProvider && !isTyped {
+		return StatePayload{}, false, nil
+	}
+
+	if isProvider {
+		state = sp.CanvasState()
+	}
+	if isTyped {
+		state.Custom, err = customOf(typed.Call(nil)[0].Interface())
+	}
+	return state, true, err
+}
+
+// customOf converts a typed state to the "custom" map. Values that are not
+// JSON objects are stored under "value".
+func customOf(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode typed state: %w", err)
+	}
+	var custom map[string]any
+	if err := json.Unmarshal(data, &custom); err != nil {
+		var value any
+		json.Unmarshal(data, &value)
+		return map[string]any{"value": value}, nil
+	}
+	return custom, nil
+}
+
+// stateSchema returns the JSON Schema of a model's "custom" state, looking
+// through adapters. It returns nil for models without typed state.
+func stateSchema(model any) json.RawMessage {
+	for {
+		w, ok := model.(wrapper)
+		if !ok {
+			break
+		}
+		model = w.canvasModel()
+	}
+	m, ok := typedState(model)
+	if !ok {
+		return nil
+	}
+	return schemaOf(m.Type().Out(0))
+}
+
+// schemaOf generates an inline JSON Schema for a Go type. Types that are
+// not JSON objects get the schema of the {"value": ...} wrapper customOf
+// puts them in.
+func schemaOf(t reflect.Type) json.RawMessage {
+	r := &jsonschema.Reflector{DoNotReference: true}
+	s := r.ReflectFromType(t)
+	if s.Type != "object" {
+		version := s.Version
+		s.Version = ""
+		props := jsonschema.NewProperties()
+		props.Set("value", s)
+		s = &jsonschema.Schema{
+			Version:    version,
+			Type:       "object",
+			Properties: props,
+			Required:   []string{"value"},
+		}
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// StateOf decodes the custom state of a canvas into T, the type its
+// TypedStateProvider returns, unwrapping the "value" of types that are not
+// JSON objects
+func StateOf[T any](state *StatePayload) (T, error) {
+	var v T
+	data, err := json.Marshal(state.Custom)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(data, &v)
+	if value, wrapped := state.Custom["value"]; err != nil && wrapped && len(state.Custom) == 1 {
+		if data, err = json.Marshal(value); err != nil {
+			return v, err
+		}
+		v = *new(T)
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return v, fmt.Errorf("failed to decode custom state: %w", err)
+	}
+	return v, nil
+}
+
+// GetStateAs queries the canvas for its state and decodes the custom part
+// into T
+func GetStateAs[T any](c *Client) (T, error) {
+	state, err := c.GetState()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return StateOf[T](state)
+}
@@ -0,0 +1,119 @@
+package canvas
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// editorState is a typed state
+type editorState struct {
+	File  string   `json:"file"`
+	Dirty bool     `json:"dirty"`
+	Tabs  []string `json:"tabs,omitempty"`
+}
+
+// structModel has typed state next to the standard fields
+type structModel struct{ state editorState }
+
+func (m structModel) CanvasState() StatePayload     { return StatePayload{Mode: "edit"} }
+func (m structModel) CanvasTypedState() editorState { return m.state }
+
+// scalarModel has a typed state that is not a JSON object
+type scalarModel int
+
+func (m scalarModel) CanvasTypedState() int { return int(m) }
+
+// pointerModel has a typed state behind a pointer
+type pointerModel struct{ state *editorState }
+
+func (m pointerModel) CanvasTypedState() *editorState { return m.state }
+
+func TestTypedState(t *testing.T) {
+	tempSocketDir(t)
+	state := editorState{File: "main.go", Dirty: true, Tabs: []string{"a", "b"}}
+
+	t.Run("struct", func(t *testing.T) {
+		startServer(t, "struct", structModel{state})
+		c := NewClient("struct")
+		raw, err := c.GetState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw.Mode != "edit" || raw.Custom["file"] != "main.go" {
+			t.Errorf("state = %+v", raw)
+		}
+		got, err := GetStateAs[editorState](c)
+		if err != nil || !reflect.DeepEqual(got, state) {
+			t.Errorf("got %+v, %v, want %+v", got, err, state)
+		}
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		startServer(t, "scalar", scalarModel(42))
+		c := NewClient("scalar")
+		raw, err := c.GetState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw.Custom["value"] != float64(42) {
+			t.Errorf("custom = %v, want the value wrapped", raw.Custom)
+		}
+		if got, err := GetStateAs[int](c); err != nil || got != 42 {
+			t.Errorf("got %d, %v, want 42", got, err)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		startServer(t, "pointer", pointerModel{&state})
+		got, err := GetStateAs[*editorState](NewClient("pointer"))
+		if err != nil || got == nil || !reflect.DeepEqual(*got, state) {
+			t.Errorf("got %+v, %v, want %+v", got, err, state)
+		}
+	})
+}
+
+func TestGetStateSchema(t *testing.T) {
+	tempSocketDir(t)
+	startServer(t, "struct", structModel{})
+	startServer(t, "scalar", scalarModel(0))
+	startServer(t, "pointer", pointerModel{})
+	startServer(t, "untyped", stateModel("x"))
+
+	// schema decodes the parts of a schema the tests look at
+	type schema struct {
+		Type       string            `json:"type"`
+		Properties map[string]schema `json:"properties"`
+		Required   []string          `json:"required"`
+	}
+	get := func(id string) schema {
+		t.Helper()
+		data, err := NewClient(id).GetStateSchema()
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		var s schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("%s: invalid schema %s", id, data)
+		}
+		return s
+	}
+
+	for _, id := range []string{"struct", "pointer"} {
+		s := get(id)
+		if s.Type != "object" || s.Properties["file"].Type != "string" || s.Properties["dirty"].Type != "boolean" ||
+			s.Properties["tabs"].Type != "array" {
+			t.Errorf("%s: schema = %+v", id, s)
+		}
+	}
+
+	s := get("scalar")
+	if s.Type != "object" || s.Properties["value"].Type != "integer" || len(s.Required) != 1 || s.Required[0] != "value" {
+		t.Errorf("scalar: schema = %+v, want the value wrapper", s)
+	}
+
+	if _, err := NewClient("untyped").GetStateSchema(); err == nil || !strings.Contains(err.Error(), "not_supported") {
+		t.Errorf("got %v for a model without typed state, want not_supported", err)
+	}
+}
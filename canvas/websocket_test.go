@@ -0,0 +1,159 @@
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// frame encodes a WebSocket frame header announcing length bytes,
+// followed by payload, masked with mask if it is not nil
+func frame(fin bool, opcode byte, length uint64, mask []byte, payload []byte) []byte {
+	b := []byte{opcode}
+	if fin {
+		b[0] |= 0x80
+	}
+	var maskBit byte
+	if mask != nil {
+		maskBit = 0x80
+	}
+	switch {
+	case length < 126:
+		b = append(b, maskBit|byte(length))
+	case length <= 0xFFFF:
+		b = append(b, maskBit|126)
+		b = binary.BigEndian.AppendUint16(b, uint16(length))
+	default:
+		b = append(b, maskBit|127)
+		b = binary.BigEndian.AppendUint64(b, length)
+	}
+	b = append(b, mask...)
+	for i, c := range payload {
+		if mask != nil {
+			c ^= mask[i%4]
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+func TestReadFrame(t *testing.T) {
+	mask := []byte{1, 2, 3, 4}
+	medium := bytes.Repeat([]byte("m"), 300)
+	large := bytes.Repeat([]byte("l"), 70000)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		limit    int
+		buffered int
+		fin      bool
+		opcode   byte
+		payload  []byte
+		err      error
+	}{
+		{name: "unmasked text", data: frame(true, wsText, 5, nil, []byte("hello")),
+			fin: true, opcode: wsText, payload: []byte("hello")},
+		{name: "masked text", data: frame(true, wsText, 5, mask, []byte("hello")),
+			fin: true, opcode: wsText, payload: []byte("hello")},
+		{name: "continuation", data: frame(false, wsContinuation, 2, nil, []byte("ab")),
+			opcode: wsContinuation, payload: []byte("ab")},
+		{name: "empty ping", data: frame(true, wsPing, 0, mask, nil),
+			fin: true, opcode: wsPing, payload: []byte{}},
+		{name: "16-bit length", data: frame(true, wsBinary, uint64(len(medium)), mask, medium),
+			fin: true, opcode: wsBinary, payload: medium},
+		{name: "64-bit length", data: frame(true, wsText, uint64(len(large)), nil, large),
+			fin: true, opcode: wsText, payload: large},
+		{name: "over the limit", data: frame(true, wsText, 11, nil, []byte("hello world")),
+			limit: 10, err: errMessageTooLarge},
+		{name: "at the limit", data: frame(true, wsText, 10, nil, []byte("helloworld")),
+			limit: 10, fin: true, opcode: wsText, payload: []byte("helloworld")},
+		{name: "over the limit with the buffered part", data: frame(true, wsText, 6, nil, []byte("hello!")),
+			limit: 10, buffered: 5, err: errMessageTooLarge},
+		{name: "huge length without a limit", data: frame(true, wsText, 1<<62, nil, nil),
+			err: errMessageTooLarge},
+		{name: "length beyond the hard cap", data: frame(true, wsText, wsMaxMessage+1, nil, nil),
+			limit: 1 << 30, err: errMessageTooLarge},
+		{name: "truncated header", data: []byte{0x81}, err: io.ErrUnexpectedEOF},
+		{name: "truncated length", data: []byte{0x81, 126, 1}, err: io.ErrUnexpectedEOF},
+		{name: "truncated payload", data: frame(true, wsText, 5, nil, []byte("he")), err: io.ErrUnexpectedEOF},
+		{name: "no frame", data: nil, err: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &wsConn{br: bufio.NewReader(bytes.NewReader(tt.data)), limit: tt.limit}
+			fin, opcode, payload, err := c.readFrame(tt.buffered)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fin != tt.fin || opcode != tt.opcode || !bytes.Equal(payload, tt.payload) {
+				t.Errorf("got fin %v opcode %#x payload %q, want fin %v opcode %#x payload %q",
+					fin, opcode, truncate(payload), tt.fin, tt.opcode, truncate(tt.payload))
+			}
+		})
+	}
+}
+
+func truncate(b []byte) []byte {
+	if len(b) > 16 {
+		return b[:16]
+	}
+	return b
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	sc := &wsConn{Conn: server, br: bufio.NewReader(server)}
+
+	// A fragmented, pretty-printed message with a ping in between arrives
+	// as one line, and the ping is answered.
+	go func() {
+		client.Write(frame(false, wsText, 9, []byte{9, 8, 7, 6}, []byte("{\n  \"a\": ")))
+		client.Write(frame(true, wsPing, 2, []byte{1, 1, 1, 1}, []byte("hi")))
+		client.Write(frame(true, wsContinuation, 3, []byte{5, 5, 5, 5}, []byte("1\n}")))
+	}()
+	cc := &wsConn{Conn: client, br: bufio.NewReader(client), client: true, limit: wsMaxMessage}
+	pong := make(chan []byte, 1)
+	go func() {
+		_, opcode, payload, err := cc.readFrame(0)
+		if err != nil || opcode != wsPong {
+			pong <- nil
+			return
+		}
+		pong <- payload
+	}()
+
+	line, err := bufio.NewReader(sc).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "{\"a\":1}\n" {
+		t.Errorf("got %q, want the compacted message", line)
+	}
+	if got := <-pong; string(got) != "hi" {
+		t.Errorf("pong payload = %q, want %q", got, "hi")
+	}
+
+	// Writes are sent as one masked frame without the newline.
+	go cc.Write([]byte("{\"b\":2}\n"))
+	msg, err := sc.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != `{"b":2}` {
+		t.Errorf("got %q from the client", msg)
+	}
+}
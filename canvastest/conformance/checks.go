@@ -0,0 +1,679 @@
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+)
+
+// checks lists every check in the order Run executes them. Mutating checks
+// come last so that close ends the run.
+var checks = []check{
+	{name: "get_state returns state", run: checkGetState},
+	{name: "get_view returns view", run: checkGetView},
+	{name: "get_state_schema returns state_schema", run: checkGetStateSchema},
+	{name: "get_view in chunks ends with a view", run: checkChunkedView},
+	{name: "subscribe acknowledges and keeps the connection open", run: checkSubscribe},
+	{name: "unknown type returns unknown_type", run: checkUnknownType},
+	{name: "malformed JSON returns parse_error", run: checkParseError},
+	{name: "connection survives a parse_error", run: checkSurvivesParseError},
+	{name: "send_key without payload returns invalid_payload", run: checkMissingPayload},
+	{name: "send_key with wrong field type returns invalid_payload", run: checkWrongFieldType},
+	{name: "send_input with non-object payload returns invalid_payload", run: checkNonObjectPayload},
+	{name: "request split across writes gets one response", run: checkPartialLine},
+	{name: "pipelined requests get responses in order", run: checkPipelining},
+	{name: "several requests on one connection", run: checkKeepAlive},
+	{name: "concurrent connections", run: checkConcurrent},
+	{name: "oversized message returns message_too_large or closes the connection", run: checkOversized},
+	{name: "unsupported requests return not_supported", run: checkNotSupported},
+	{name: "failing get_state returns state_error", run: checkStateError},
+	{name: "rejected key returns key_error", run: checkKeyError},
+	{name: "rejected input returns input_error", run: checkInputError},
+	{name: "unauthenticated request returns unauthorized", run: checkUnauthorized},
+	{name: "connection beyond the limit gets too_many_connections", run: checkConnectionLimit},
+	{name: "batch answers each request in order", run: checkBatch},
+	{name: "batch rejects nested subscribe", run: checkBatchSubscribe},
+	{name: "stats returns stats_result", run: checkStats},
+	{name: "JSON-RPC request gets a JSON-RPC response", run: checkJSONRPC},
+	{name: "JSON-RPC unknown method returns -32601", run: checkJSONRPCUnknownMethod},
+	{name: "JSON-RPC batch gets an array of responses", run: checkJSONRPCBatch},
+	{name: "send_key acknowledges", mutating: true, run: checkSendKey},
+	{name: "send_input acknowledges", mutating: true, run: checkSendInput},
+	{name: "close reports the outcome", mutating: true, run: checkClose},
+}
+
+func checkGetState(t *tester) error {
+	if t.opts.FailingState {
+		return skipError("the TUI is set up to fail get_state")
+	}
+	resp, err := t.roundTrip(canvas.MsgGetState, nil)
+	if err != nil {
+		return err
+	}
+	var state canvas.StatePayload
+	return optional(resp, canvas.MsgState, &state)
+}
+
+func checkGetView(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgGetView, nil)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := optional(resp, canvas.MsgView, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["content"]; !ok {
+		return errors.New("view payload has no content")
+	}
+	var view canvas.ViewPayload
+	return expectType(resp, canvas.MsgView, &view)
+}
+
+func checkChunkedView(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	size := canvas.MinChunkSize
+	resp, err := c.request(canvas.MsgGetView, canvas.ViewRequestPayload{ChunkSize: size})
+	for i := 1; err == nil && resp.Type == canvas.MsgViewChunk; i++ {
+		var chunk canvas.ViewChunkPayload
+		if err := expectType(resp, canvas.MsgViewChunk, &chunk); err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+		if len(chunk.Content) > size {
+			return fmt.Errorf("chunk %d has %d bytes, want at most %d", i, len(chunk.Content), size)
+		}
+		resp, err = c.read()
+	}
+	if err != nil {
+		return err
+	}
+	var view canvas.ViewPayload
+	if err := optional(resp, canvas.MsgView, &view); err != nil {
+		return err
+	}
+	if len(view.Content) > size {
+		return fmt.Errorf("last part has %d bytes, want at most %d", len(view.Content), size)
+	}
+	return nil
+}
+
+func checkGetStateSchema(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgGetStateSchema, nil)
+	if err != nil {
+		return err
+	}
+	var payload canvas.StateSchemaPayload
+	if err := optional(resp, canvas.MsgStateSchema, &payload); err != nil {
+		return err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(payload.Schema, &schema); err != nil {
+		return errors.New("schema is not a JSON object")
+	}
+	return nil
+}
+
+func checkSubscribe(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.request(canvas.MsgSubscribe, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectType(resp, canvas.MsgAck, nil); err != nil {
+		return err
+	}
+
+	// Events may arrive at any time; the connection just must not be
+	// closed on the subscriber.
+	c.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	for {
+		line, err := c.r.ReadBytes('\n')
+		if err != nil {
+			if isTimeout(err) {
+				return nil
+			}
+			return fmt.Errorf("server closed the subscription: %v", err)
+		}
+		var event canvas.Message
+		if json.Unmarshal(line, &event) != nil || event.Type == "" {
+			return fmt.Errorf("event is not a JSON message: %q", strings.TrimSpace(string(line)))
+		}
+	}
+}
+
+func checkUnknownType(t *tester) error {
+	resp, err := t.roundTrip("no_such_type", nil)
+	if err != nil {
+		return err
+	}
+	return expectError(resp, canvas.CodeUnknownType)
+}
+
+func checkParseError(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.write("{not json\n"); err != nil {
+		return err
+	}
+	resp, err := c.read()
+	if err != nil {
+		return err
+	}
+	return expectError(resp, canvas.CodeParseError)
+}
+
+func checkSurvivesParseError(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.write("{not json\n"); err != nil {
+		return err
+	}
+	if _, err := c.read(); err != nil {
+		return err
+	}
+	resp, err := c.request(canvas.MsgGetView, nil)
+	if err != nil {
+		return fmt.Errorf("request after parse_error: %w", err)
+	}
+	return optional(resp, canvas.MsgView, &canvas.ViewPayload{})
+}
+
+func checkMissingPayload(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgSendKey, nil)
+	if err != nil {
+		return err
+	}
+	return expectError(resp, canvas.CodeInvalidPayload)
+}
+
+func checkWrongFieldType(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgSendKey, map[string]any{"key": 42})
+	if err != nil {
+		return err
+	}
+	if err := expectError(resp, canvas.CodeInvalidPayload); err != nil {
+		return err
+	}
+	var payload canvas.ErrorPayload
+	resp.ParsePayload(&payload)
+	if !strings.Contains(payload.Message, "key") {
+		return fmt.Errorf("error does not name the field: %q", payload.Message)
+	}
+	return nil
+}
+
+func checkNonObjectPayload(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgSendInput, []int{1, 2})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, canvas.CodeInvalidPayload)
+}
+
+func checkPartialLine(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.write(`{"type":"get_`); err != nil {
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := c.write("view\"}\n"); err != nil {
+		return err
+	}
+	resp, err := c.read()
+	if err != nil {
+		return err
+	}
+	return optional(resp, canvas.MsgView, &canvas.ViewPayload{})
+}
+
+func checkPipelining(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Requests with distinct answers, in one write.
+	if err := c.write("{\"type\":\"get_view\"}\n{\"type\":\"no_such_type\"}\n{\"type\":\"get_view\"}\n"); err != nil {
+		return err
+	}
+	for i, unknown := range []bool{false, true, false} {
+		resp, err := c.read()
+		if err != nil {
+			return fmt.Errorf("response %d: %w", i+1, err)
+		}
+		if unknown {
+			if err := expectError(resp, canvas.CodeUnknownType); err != nil {
+				return fmt.Errorf("response %d: %w", i+1, err)
+			}
+		} else if resp.Type != canvas.MsgView && resp.Type != canvas.MsgError {
+			return fmt.Errorf("response %d: got %q, want %q", i+1, resp.Type, canvas.MsgView)
+		}
+	}
+	return nil
+}
+
+func checkKeepAlive(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.request(canvas.MsgGetView, nil); err != nil {
+			return fmt.Errorf("request %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func checkConcurrent(t *tester) error {
+	const n = 4
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := t.roundTrip(canvas.MsgGetView, nil)
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkOversized(t *tester) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+
+	// The server must refuse the message, by answering message_too_large
+	// or by dropping the connection, and keep serving others. The type is
+	// unknown, so a server that reads the message answers unknown_type
+	// without side effects.
+	text := strings.Repeat("x", t.oversized)
+	msg, _ := canvas.NewMessage("conformance_oversized", canvas.InputPayload{Text: text})
+	data, _ := json.Marshal(msg)
+	go func() {
+		c.write(string(data) + "\n")
+	}()
+	resp, err := c.read()
+	c.Close()
+	if err == nil {
+		if err := expectError(resp, "message_too_large"); err != nil {
+			return fmt.Errorf("%d-byte message: %w", t.oversized, err)
+		}
+	} else if isTimeout(err) {
+		return errors.New("no response and the connection stayed open")
+	}
+
+	resp, err = t.roundTrip(canvas.MsgGetView, nil)
+	if err != nil {
+		return fmt.Errorf("server unusable afterwards: %w", err)
+	}
+	return optional(resp, canvas.MsgView, &canvas.ViewPayload{})
+}
+
+// requestPayloads are valid payloads for the requests that need one
+var requestPayloads = map[canvas.MessageType]any{
+	canvas.MsgSendKey:   canvas.KeyPayload{Key: "right"},
+	canvas.MsgSendInput: canvas.InputPayload{Text: "a"},
+}
+
+func checkNotSupported(t *tester) error {
+	if len(t.opts.Unsupported) == 0 {
+		return skipError("no unsupported request types given")
+	}
+	for _, msgType := range t.opts.Unsupported {
+		resp, err := t.roundTrip(msgType, requestPayloads[msgType])
+		if err != nil {
+			return fmt.Errorf("%s: %w", msgType, err)
+		}
+		if err := expectError(resp, "not_supported"); err != nil {
+			return fmt.Errorf("%s: %w", msgType, err)
+		}
+	}
+	return nil
+}
+
+func checkStateError(t *tester) error {
+	if !t.opts.FailingState {
+		return skipError("the TUI is not set up to fail get_state")
+	}
+	resp, err := t.roundTrip(canvas.MsgGetState, nil)
+	if err != nil {
+		return err
+	}
+	return expectError(resp, "state_error")
+}
+
+func checkKeyError(t *tester) error {
+	if t.opts.RejectedKey == "" {
+		return skipError("no rejected key given")
+	}
+	resp, err := t.roundTrip(canvas.MsgSendKey, canvas.KeyPayload{Key: t.opts.RejectedKey})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, "key_error")
+}
+
+func checkInputError(t *tester) error {
+	if t.opts.RejectedInput == "" {
+		return skipError("no rejected input given")
+	}
+	resp, err := t.roundTrip(canvas.MsgSendInput, canvas.InputPayload{Text: t.opts.RejectedInput})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, "input_error")
+}
+
+func checkUnauthorized(t *tester) error {
+	if t.opts.Unauthenticated == nil {
+		return skipError("no unauthenticated dialer given")
+	}
+	c, err := t.connectWith(t.opts.Unauthenticated)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.request(canvas.MsgGetState, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectError(resp, "unauthorized"); err != nil {
+		return err
+	}
+	if resp, err := c.read(); err == nil {
+		return fmt.Errorf("got %q after unauthorized, want the connection closed", resp.Type)
+	}
+	return nil
+}
+
+func checkConnectionLimit(t *tester) error {
+	limit := t.opts.MaxConnections
+	if limit <= 0 {
+		return skipError("no connection limit given")
+	}
+
+	// Fill the limit with connections the server has answered on, so
+	// they are surely counted.
+	var held []*conn
+	defer func() {
+		for _, c := range held {
+			c.Close()
+		}
+	}()
+	for i := 0; i < limit; i++ {
+		c, err := t.connect()
+		if err != nil {
+			return fmt.Errorf("connection %d: %w", i+1, err)
+		}
+		held = append(held, c)
+		if _, err := c.request(canvas.MsgGetView, nil); err != nil {
+			return fmt.Errorf("connection %d: %w", i+1, err)
+		}
+	}
+
+	if err := t.refused(limit + 1); err != nil {
+		return err
+	}
+
+	// Later checks need the connections back once these are closed.
+	for _, c := range held {
+		c.Close()
+	}
+	held = nil
+	deadline := time.Now().Add(t.timeout)
+	for {
+		resp, err := t.roundTrip(canvas.MsgGetView, nil)
+		if err == nil && expectError(resp, "too_many_connections") != nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("connections are still refused after the others closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// refused checks that connection n is refused with too_many_connections
+func (t *tester) refused(n int) error {
+	c, err := t.connect()
+	if err != nil {
+		// Dialers that authenticate get the refusal as the answer to it.
+		var protoErr *canvas.ProtocolError
+		if errors.As(err, &protoErr) && protoErr.Code == canvas.CodeTooManyConnections {
+			return nil
+		}
+		return err
+	}
+	defer c.Close()
+	resp, err := c.read()
+	if err != nil {
+		return fmt.Errorf("connection %d: %w", n, err)
+	}
+	return expectError(resp, "too_many_connections")
+}
+
+func checkBatch(t *tester) error {
+	getView, _ := canvas.NewMessage(canvas.MsgGetView, nil)
+	unknown, _ := canvas.NewMessage("no_such_type", nil)
+	resp, err := t.roundTrip(canvas.MsgBatch, canvas.BatchPayload{
+		Messages: []canvas.Message{*getView, *unknown},
+	})
+	if err != nil {
+		return err
+	}
+	var result canvas.BatchResultPayload
+	if err := optional(resp, canvas.MsgBatchResult, &result); err != nil {
+		return err
+	}
+	if len(result.Responses) != 2 {
+		return fmt.Errorf("got %d responses for 2 requests", len(result.Responses))
+	}
+	if r := result.Responses[0]; r.Type != canvas.MsgView && r.Type != canvas.MsgError {
+		return fmt.Errorf("response 1: got %q, want %q", r.Type, canvas.MsgView)
+	}
+	if err := expectError(&result.Responses[1], canvas.CodeUnknownType); err != nil {
+		return fmt.Errorf("response 2: %w", err)
+	}
+	return nil
+}
+
+func checkBatchSubscribe(t *tester) error {
+	subscribe, _ := canvas.NewMessage(canvas.MsgSubscribe, nil)
+	resp, err := t.roundTrip(canvas.MsgBatch, canvas.BatchPayload{
+		Messages: []canvas.Message{*subscribe},
+	})
+	if err != nil {
+		return err
+	}
+	return expectError(resp, canvas.CodeInvalidPayload)
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code int `json:"code"`
+		Data struct {
+			Code string `json:"code"`
+		} `json:"data"`
+	} `json:"error"`
+	ID json.RawMessage `json:"id"`
+}
+
+// rpcRoundTrip sends a JSON-RPC line on a fresh connection and decodes the
+// response line into v
+func (t *tester) rpcRoundTrip(line string, v any) error {
+	c, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.write(line + "\n"); err != nil {
+		return err
+	}
+	c.SetReadDeadline(time.Now().Add(c.timeout))
+	resp, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("no response: %w", err)
+	}
+	if err := json.Unmarshal(resp, v); err != nil {
+		return fmt.Errorf("response is not JSON-RPC: %q", strings.TrimSpace(string(resp)))
+	}
+	return nil
+}
+
+func checkResponse(resp rpcResponse, id string) error {
+	if resp.JSONRPC != "2.0" {
+		return fmt.Errorf("jsonrpc is %q, want \"2.0\"", resp.JSONRPC)
+	}
+	if string(resp.ID) != id {
+		return fmt.Errorf("id is %s, want %s", resp.ID, id)
+	}
+	return nil
+}
+
+func checkJSONRPC(t *tester) error {
+	var resp rpcResponse
+	if err := t.rpcRoundTrip(`{"jsonrpc":"2.0","method":"canvas.getView","id":7}`, &resp); err != nil {
+		return err
+	}
+	if err := checkResponse(resp, "7"); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		if resp.Error.Data.Code == "not_supported" {
+			return skipError("get_view is not supported by the server")
+		}
+		return fmt.Errorf("got error %d", resp.Error.Code)
+	}
+	var view canvas.ViewPayload
+	if err := json.Unmarshal(resp.Result, &view); err != nil {
+		return errors.New("result is not a view")
+	}
+	return nil
+}
+
+func checkJSONRPCUnknownMethod(t *tester) error {
+	var resp rpcResponse
+	if err := t.rpcRoundTrip(`{"jsonrpc":"2.0","method":"canvas.noSuchMethod","id":"x"}`, &resp); err != nil {
+		return err
+	}
+	if err := checkResponse(resp, `"x"`); err != nil {
+		return err
+	}
+	if resp.Error == nil || resp.Error.Code != -32601 {
+		return errors.New("want error -32601")
+	}
+	return nil
+}
+
+func checkJSONRPCBatch(t *tester) error {
+	var resps []rpcResponse
+	line := `[{"jsonrpc":"2.0","method":"canvas.getView","id":1},` +
+		`{"jsonrpc":"2.0","method":"canvas.getView"},` +
+		`{"jsonrpc":"2.0","method":"canvas.noSuchMethod","id":2}]`
+	if err := t.rpcRoundTrip(line, &resps); err != nil {
+		return err
+	}
+	// The notification is not answered.
+	if len(resps) != 2 {
+		return fmt.Errorf("got %d responses, want 2", len(resps))
+	}
+	// Responses may come in any order.
+	if string(resps[0].ID) == "2" {
+		resps[0], resps[1] = resps[1], resps[0]
+	}
+	if err := checkResponse(resps[0], "1"); err != nil {
+		return err
+	}
+	return checkResponse(resps[1], "2")
+}
+
+func checkStats(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgStats, nil)
+	if err != nil {
+		return err
+	}
+	var stats canvas.StatsPayload
+	if err := optional(resp, canvas.MsgStatsResult, &stats); err != nil {
+		return err
+	}
+	if stats.ActiveConnections < 1 {
+		return errors.New("active_connections does not count the asking connection")
+	}
+	for t, ms := range stats.Messages {
+		if len(ms.Latency.Counts) != len(ms.Latency.Bounds) {
+			return fmt.Errorf("latency of %s has %d counts for %d bounds", t, len(ms.Latency.Counts), len(ms.Latency.Bounds))
+		}
+	}
+	return nil
+}
+
+func checkSendKey(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgSendKey, canvas.KeyPayload{Key: "right"})
+	if err != nil {
+		return err
+	}
+	return optional(resp, canvas.MsgAck, nil)
+}
+
+func checkSendInput(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgSendInput, canvas.InputPayload{Text: "a"})
+	if err != nil {
+		return err
+	}
+	return optional(resp, canvas.MsgAck, nil)
+}
+
+func checkClose(t *tester) error {
+	resp, err := t.roundTrip(canvas.MsgClose, nil)
+	if err != nil {
+		return err
+	}
+	var result canvas.ClosePayload
+	return expectType(resp, canvas.MsgAck, &result)
+}
+
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
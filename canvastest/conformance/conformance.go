@@ -0,0 +1,269 @@
+// Package conformance checks that an implementation of the canvas socket
+// protocol behaves like canvas.Server: message types, error codes and
+// framing. It is meant for servers written in other languages or by hand.
+package conformance
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+)
+
+// Dialer opens a new connection to the server under test
+type Dialer func() (net.Conn, error)
+
+// Unix dials a Unix socket
+func Unix(socket string) Dialer {
+	return func() (net.Conn, error) {
+		return net.DialTimeout("unix", socket, 5*time.Second)
+	}
+}
+
+// Client dials through a canvas client, so that any address canvas.Dial
+// accepts can be checked, authenticating with the client's token
+func Client(c *canvas.Client) Dialer {
+	return c.DialConn
+}
+
+// Options configures Run. The checks of error codes that only a suitably
+// set up server can produce are skipped unless the options describe how
+// to trigger them.
+type Options struct {
+	// Timeout bounds each read from the server (5s if zero)
+	Timeout time.Duration
+
+	// Mutating enables checks with side effects on the TUI: sending a
+	// key, sending input and finally closing it. They are skipped otherwise.
+	Mutating bool
+
+	// OversizedBytes is the size of the oversized message check, which
+	// must exceed the server's limit (4 MiB if zero)
+	OversizedBytes int
+
+	// RejectedKey is a key the TUI refuses, checked for key_error
+	RejectedKey string
+
+	// RejectedInput is text the TUI refuses, checked for input_error
+	RejectedInput string
+
+	// FailingState means the TUI cannot report its state, so get_state
+	// must return state_error rather than state
+	FailingState bool
+
+	// Unsupported lists request types the TUI does not implement, checked
+	// for not_supported
+	Unsupported []canvas.MessageType
+
+	// Unauthenticated dials the server without authenticating, checked for
+	// unauthorized. Use it for network listeners that require a token.
+	Unauthenticated Dialer
+
+	// MaxConnections is the server's connection limit, checked for
+	// too_many_connections
+	MaxConnections int
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Details string `json:"details,omitempty"`
+}
+
+// Status of a check
+type Status string
+
+const (
+	Pass Status = "pass"
+	Fail Status = "fail"
+	Skip Status = "skip"
+)
+
+// Passed reports whether no check failed
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return false
+		}
+	}
+	return true
+}
+
+// skipError marks a check as skipped rather than failed
+type skipError string
+
+func (e skipError) Error() string { return string(e) }
+
+// check is one conformance check
+type check struct {
+	name     string
+	mutating bool
+	run      func(t *tester) error
+}
+
+// Run executes every check against the server and returns their results
+// in order. Checks run on fresh connections, so one failure does not
+// affect the others.
+func Run(dial Dialer, opts *Options) []Result {
+	if opts == nil {
+		opts = &Options{}
+	}
+	t := &tester{dial: dial, opts: opts, timeout: 5 * time.Second, oversized: 4 << 20}
+	if opts.Timeout > 0 {
+		t.timeout = opts.Timeout
+	}
+	if opts.OversizedBytes > 0 {
+		t.oversized = opts.OversizedBytes
+	}
+
+	var results []Result
+	for _, c := range checks {
+		r := Result{Name: c.name, Status: Pass}
+		if c.mutating && !opts.Mutating {
+			r.Status, r.Details = Skip, "has side effects; enable Mutating"
+			results = append(results, r)
+			continue
+		}
+
+		var skip skipError
+		if err := c.run(t); errors.As(err, &skip) {
+			r.Status, r.Details = Skip, err.Error()
+		} else if err != nil {
+			r.Status, r.Details = Fail, err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// tester opens connections for the checks
+type tester struct {
+	dial      Dialer
+	opts      *Options
+	timeout   time.Duration
+	oversized int
+}
+
+// conn is a connection speaking the line protocol
+type conn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+}
+
+func (t *tester) connect() (*conn, error) {
+	return t.connectWith(t.dial)
+}
+
+func (t *tester) connectWith(dial Dialer) (*conn, error) {
+	c, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return &conn{Conn: c, r: bufio.NewReader(c), timeout: t.timeout}, nil
+}
+
+// write sends raw bytes
+func (c *conn) write(data string) error {
+	c.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := c.Write([]byte(data)); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// read returns the next message, checking that it is one line of JSON
+// with a type
+func (c *conn) read() (*canvas.Message, error) {
+	c.SetReadDeadline(time.Now().Add(c.timeout))
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("no response: %w", err)
+	}
+	var msg canvas.Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, fmt.Errorf("response is not a JSON message: %q", strings.TrimSpace(string(line)))
+	}
+	if msg.Type == "" {
+		return nil, fmt.Errorf("response has no type: %q", strings.TrimSpace(string(line)))
+	}
+	return &msg, nil
+}
+
+// request sends a message and returns the response
+func (c *conn) request(msgType canvas.MessageType, payload any) (*canvas.Message, error) {
+	msg, err := canvas.NewMessage(msgType, payload)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(msg)
+	if err := c.write(string(data) + "\n"); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// roundTrip sends one request on a fresh connection
+func (t *tester) roundTrip(msgType canvas.MessageType, payload any) (*canvas.Message, error) {
+	c, err := t.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.request(msgType, payload)
+}
+
+// expectType checks a response's type and decodes its payload into v
+func expectType(msg *canvas.Message, want canvas.MessageType, v any) error {
+	if msg.Type != want {
+		return fmt.Errorf("got %q, want %q%s", msg.Type, want, errorDetails(msg))
+	}
+	if v == nil {
+		return nil
+	}
+	if err := msg.ParsePayload(v); err != nil {
+		return fmt.Errorf("%s payload does not match the schema: %v", want, err)
+	}
+	return nil
+}
+
+// expectError checks that a response is an error with the given code
+func expectError(msg *canvas.Message, code string) error {
+	var payload canvas.ErrorPayload
+	if err := expectType(msg, canvas.MsgError, &payload); err != nil {
+		return err
+	}
+	if payload.Code != code {
+		return fmt.Errorf("got error code %q, want %q", payload.Code, code)
+	}
+	if payload.Message == "" {
+		return errors.New("error has no message")
+	}
+	return nil
+}
+
+// errorDetails describes an error response for failure messages
+func errorDetails(msg *canvas.Message) string {
+	if msg.Type != canvas.MsgError {
+		return ""
+	}
+	var payload canvas.ErrorPayload
+	msg.ParsePayload(&payload)
+	return fmt.Sprintf(" (%s: %s)", payload.Code, payload.Message)
+}
+
+// optional passes a response of the wanted type and skips a not_supported
+// error, which servers may return for optional capabilities
+func optional(msg *canvas.Message, want canvas.MessageType, v any) error {
+	var payload canvas.ErrorPayload
+	if msg.Type == canvas.MsgError && msg.ParsePayload(&payload) == nil && payload.Code == canvas.CodeNotSupported {
+		return skipError("not supported by the server: " + payload.Message)
+	}
+	return expectType(msg, want, v)
+}
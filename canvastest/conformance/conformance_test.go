@@ -0,0 +1,151 @@
+package conformance_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+	"github.com/AlqattanDev/opencode-canvas/canvastest/conformance"
+)
+
+// model implements every optional interface of canvas.Server
+type model struct {
+	mu     sync.Mutex
+	keys   []string
+	input  string
+	closed bool
+}
+
+type state struct {
+	Keys  int    `json:"keys"`
+	Input string `json:"input"`
+}
+
+func (m *model) CanvasState() canvas.StatePayload {
+	return canvas.StatePayload{Mode: "test"}
+}
+
+func (m *model) CanvasTypedState() state {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return state{Keys: len(m.keys), Input: m.input}
+}
+
+func (m *model) CanvasView() string {
+	return "\x1b[1mconformance\x1b[0m\n" + "──────────"
+}
+
+func (m *model) HandleCanvasKey(key string, r rune) error {
+	if key == "reject" {
+		return errors.New("no such key")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *model) HandleCanvasInput(text string) error {
+	if text == "reject" {
+		return errors.New("read-only")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.input += text
+	return nil
+}
+
+func (m *model) HandleCanvasClose() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// brokenState cannot be encoded
+type brokenState struct{}
+
+func (brokenState) MarshalJSON() ([]byte, error) { return nil, errors.New("broken") }
+
+// brokenModel has a typed state that fails to encode
+type brokenModel struct{}
+
+func (brokenModel) CanvasTypedState() brokenState { return brokenState{} }
+
+// startServer starts a canvas serving model
+func startServer(t *testing.T, model any, opts ...canvas.ServerOption) *canvas.Server {
+	t.Helper()
+	s, err := canvas.NewServer("conformance", append([]canvas.ServerOption{canvas.WithSocketDir(t.TempDir())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetModel(model)
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestServerConforms(t *testing.T) {
+	m := &model{}
+	full := startServer(t, m, canvas.WithMaxConnections(8))
+	bare := startServer(t, struct{}{})
+	broken := startServer(t, brokenModel{},
+		canvas.WithListen("tcp://127.0.0.1:0"), canvas.WithToken("secret"))
+
+	remote, err := canvas.Dial(broken.Metadata().Addrs[0] + "?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(broken.Metadata().Addrs[0])
+
+	setups := []struct {
+		name string
+		dial conformance.Dialer
+		opts *conformance.Options
+	}{
+		{"full", conformance.Unix(full.SocketPath()), &conformance.Options{
+			Mutating:       true,
+			RejectedKey:    "reject",
+			RejectedInput:  "reject",
+			MaxConnections: 8,
+		}},
+		{"bare", conformance.Unix(bare.SocketPath()), &conformance.Options{
+			Unsupported: []canvas.MessageType{
+				canvas.MsgGetState, canvas.MsgGetView, canvas.MsgGetStateSchema, canvas.MsgSendKey, canvas.MsgSendInput,
+			},
+		}},
+		{"remote", conformance.Client(remote), &conformance.Options{
+			FailingState:    true,
+			Unauthenticated: func() (net.Conn, error) { return net.Dial("tcp", u.Host) },
+		}},
+	}
+
+	// Every check must pass in some setup and fail in none.
+	passed := make(map[string]bool)
+	var names []string
+	for _, setup := range setups {
+		for _, r := range conformance.Run(setup.dial, setup.opts) {
+			if _, seen := passed[r.Name]; !seen {
+				names = append(names, r.Name)
+			}
+			passed[r.Name] = passed[r.Name] || r.Status == conformance.Pass
+			if r.Status == conformance.Fail {
+				t.Errorf("%s: %s: %s", setup.name, r.Name, r.Details)
+			}
+		}
+	}
+	for _, name := range names {
+		if !passed[name] {
+			t.Errorf("%s: skipped in every setup", name)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.keys) == 0 || m.input == "" || !m.closed {
+		t.Errorf("mutating checks did not reach the model: keys %v, input %q, closed %v", m.keys, m.input, m.closed)
+	}
+}
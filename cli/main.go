@@ -1,24 +1,56 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/AlqattanDev/opencode-canvas/canvas"
+	"github.com/AlqattanDev/opencode-canvas/canvastest/conformance"
+	"github.com/AlqattanDev/opencode-canvas/render"
+)
+
+// Global options selecting a remote host, see newClient
+var (
+	remoteHost string
+	remoteCmd  string
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	global := flag.NewFlagSet("opencode-canvas", flag.ExitOnError)
+	global.Usage = printUsage
+	global.StringVar(&remoteHost, "remote", "", "reach canvases on [user@]host over ssh")
+	global.StringVar(&remoteCmd, "remote-cmd", "", "command forwarding stdio to {socket} on {host}")
+	global.Parse(os.Args[1:])
+
+	if global.NArg() < 1 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	cmd := os.Args[1]
-	args := os.Args[2:]
+	cmd := global.Arg(0)
+	args := global.Args()[1:]
+
+	// Commands that work on the socket directory itself run on the
+	// remote host.
+	if remoteHost != "" && (cmd == "list" || cmd == "gc" || cmd == "spawn" || cmd == "audit") {
+		runRemote(cmd, args)
+		return
+	}
 
 	switch cmd {
 	case "state":
@@ -32,11 +64,27 @@ func main() {
 	case "close":
 		cmdClose(args)
 	case "list":
-		cmdList()
+		cmdList(args)
+	case "gc":
+		cmdGC()
 	case "spawn":
 		cmdSpawn(args)
 	case "ping":
 		cmdPing(args)
+	case "screenshot":
+		cmdScreenshot(args)
+	case "pipe":
+		cmdPipe(args)
+	case "schema":
+		cmdSchema(args)
+	case "call":
+		cmdCall(args)
+	case "audit":
+		cmdAudit(args)
+	case "stats":
+		cmdStats(args)
+	case "conformance":
+		cmdConformance(args)
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -54,18 +102,51 @@ USAGE:
 
 COMMANDS:
     state <id>              Get canvas state as JSON
+        --all               Get the state of every live canvas, keyed by ID
+        --schema            Get the JSON Schema of the custom state
     view <id>               Get rendered view (with ANSI codes)
+        --format <fmt>      Output as ansi, text, html, svg or png
+        --all               Get every live canvas's view as JSON, keyed by ID
+        --parallel <n>      Canvases queried at once with --all (default 8)
     key <id> <key>          Send a key press (e.g., "enter", "tab", "ctrl+c")
     input <id> <text>       Send text input
     close <id>              Request canvas to close
-    list                    List active canvases
+    list [--json]           List active canvases with PID, app, start time, pane and cwd
+    gc                      Remove sockets left behind by crashed canvases
     spawn <id> <cmd...>     Spawn a command as a canvas in tmux
     ping <id>               Check if canvas is responsive
+    screenshot <id> <file>  Render the view to a PNG image ("-" for stdout)
+    pipe <socket>           Connect stdin and stdout to a canvas socket
+    call <id> <type> [json] Send a message of any type, e.g. one the app registered,
+                            and print the response payload
+    stats <id>              Show request counts, latencies, connections and traffic
+        --json              Print the stats as JSON
+        --prometheus        Print the stats in the Prometheus text format
+    audit <id|file>         Show the audit log of a canvas started with CANVAS_AUDIT_LOG
+        --type <type>       Only records of this message type
+        --since <duration>  Only records newer than this, e.g. 1h
+        -n <count>          Only the last count records
+        --follow            Keep printing records as they are written
+        --json              Print the records as JSON lines
+    schema [type]           Print the JSON Schema of the protocol or one message type
+    conformance <id>        Check that a protocol server behaves like canvas.Server
+        --mutating          Also send a key, input and finally close
+
+GLOBAL OPTIONS (before the command):
+    --remote <user@host>    Reach canvases on another machine over ssh
+    --remote-cmd <cmd>      Replace "ssh -W {socket} {host}" with a command
+                            forwarding stdio to {socket} on {host}
+
+Wherever <id> is expected, a socket path or an address such as
+tcp://host:port/<id> or ws://host:port/<id> also works. Network canvases
+that require a token read it from "?token=..." or CANVAS_TOKEN.
 
 EXAMPLES:
     # Query a canvas
     opencode-canvas state my-tui
     opencode-canvas view my-tui
+    opencode-canvas view my-tui --format html > view.html
+    opencode-canvas screenshot my-tui view.png
 
     # Send input
     opencode-canvas key my-tui enter
@@ -74,9 +155,43 @@ EXAMPLES:
     # Spawn a TUI in tmux
     opencode-canvas spawn my-tui ./my-app --flag
 
+    # Drive a canvas on a dev box
+    opencode-canvas --remote me@devbox view my-tui
+    opencode-canvas --remote me@devbox --remote-cmd \
+        "ssh {host} opencode-canvas pipe {socket}" state my-tui
+
 ENVIRONMENT:
     CANVAS_ID               Default canvas ID
-    OPENCODE_CANVAS=1       Enable canvas mode in wrapped TUIs`)
+    OPENCODE_CANVAS=1       Enable canvas mode in wrapped TUIs
+    CANVAS_AUDIT_LOG=<path> Record control requests to wrapped TUIs in a file
+    CANVAS_AUDIT_REDACT_INPUT=1
+                            Leave the text of send_input out of the audit log
+    CANVAS_METRICS_ADDR=<a> Serve Prometheus metrics of wrapped TUIs on http://<a>/metrics
+    CANVAS_LOG=<path>       Append client and canvas logs to a file
+    CANVAS_LOG_LEVEL=<lvl>  debug, info (default), warn or error
+
+EXIT CODES:
+    1    Failure
+    2    Invalid flags
+    3    No canvas at the address
+    4    The app does not support the request
+    5    The app did not respond in time
+    6    The app answered with an error`)
+}
+
+// parseFlags parses flags that may appear before, between or after the
+// positional arguments and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
 }
 
 func getID(args []string) string {
@@ -91,32 +206,171 @@ func getID(args []string) string {
 	return ""
 }
 
+// newClient connects to a canvas by ID, socket path or URL, on the
+// --remote host if one is given
+func newClient(target string) *canvas.Client {
+	var opts []canvas.ClientOption
+	if remoteHost != "" && !strings.Contains(target, "://") {
+		target = "ssh://" + remoteHost + "/" + strings.TrimPrefix(target, "/")
+	}
+	if remoteCmd != "" {
+		opts = append(opts, canvas.WithSSHCommand(remoteCmd))
+	}
+
+	client, err := canvas.Dial(target, opts...)
+	if err != nil {
+		fail(err)
+	}
+	return client
+}
+
+// Exit codes, so that scripts can tell failures apart. Invalid flags exit
+// with 2.
+const (
+	exitFailure      = 1
+	exitNotFound     = 3
+	exitNotSupported = 4
+	exitTimeout      = 5
+	exitCanvasError  = 6
+)
+
+// fail prints err, with a hint for the common canvas errors, and exits
+// with the matching code
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	code, hint := exitCode(err)
+	if hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+	os.Exit(code)
+}
+
+// exitCode picks the exit code for err and a hint on what to do about it
+func exitCode(err error) (code int, hint string) {
+	var protoErr *canvas.ProtocolError
+	switch {
+	case errors.Is(err, canvas.ErrCanvasNotFound):
+		return exitNotFound, "run 'opencode-canvas list' to see the running canvases"
+	case errors.Is(err, canvas.ErrNotSupported):
+		return exitNotSupported, "the app does not implement this; 'opencode-canvas list' shows its capabilities"
+	case errors.Is(err, canvas.ErrTimeout):
+		return exitTimeout, "the app may be busy or frozen; check its terminal and try again"
+	case errors.As(err, &protoErr):
+		if protoErr.Code == canvas.CodeUnauthorized {
+			return exitCanvasError, "set CANVAS_TOKEN or add ?token=... to the address"
+		}
+		return exitCanvasError, ""
+	}
+	return exitFailure, ""
+}
+
 func cmdState(args []string) {
+	fs := flag.NewFlagSet("state", flag.ExitOnError)
+	all := fs.Bool("all", false, "query every live canvas")
+	parallel := fs.Int("parallel", canvas.DefaultParallel, "canvases queried at once with --all")
+	schema := fs.Bool("schema", false, "print the JSON Schema of the custom state instead")
+	args = parseFlags(fs, args)
+
+	if *all {
+		printOverview(canvas.OverviewOptions{State: true, Parallel: *parallel}, render.FormatANSI)
+		return
+	}
+
 	id := getID(args)
-	client := canvas.NewClient(id)
-	
+	client := newClient(id)
+
+	if *schema {
+		data, err := client.GetStateSchema()
+		if err != nil {
+			fail(err)
+		}
+		var out bytes.Buffer
+		json.Indent(&out, data, "", "  ")
+		fmt.Println(out.String())
+		return
+	}
+
 	state, err := client.GetState()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err)
 	}
-	
+
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	enc.Encode(state)
 }
 
 func cmdView(args []string) {
+	fs := flag.NewFlagSet("view", flag.ExitOnError)
+	formatName := fs.String("format", "ansi", "output format: ansi, text, html, svg or png")
+	all := fs.Bool("all", false, "query every live canvas")
+	parallel := fs.Int("parallel", canvas.DefaultParallel, "canvases queried at once with --all")
+	args = parseFlags(fs, args)
+
+	format, err := render.ParseFormat(*formatName)
+	if err != nil {
+		fail(err)
+	}
+
+	if *all {
+		if format == render.FormatPNG {
+			fmt.Fprintln(os.Stderr, "Error: --all does not support png")
+			os.Exit(1)
+		}
+		printOverview(canvas.OverviewOptions{View: true, Parallel: *parallel}, format)
+		return
+	}
+
 	id := getID(args)
-	client := canvas.NewClient(id)
-	
+	client := newClient(id)
+
+	// Raw views are passed through as they arrive.
+	if format == render.FormatANSI {
+		if err := client.StreamView(os.Stdout, canvas.DefaultChunkSize); err != nil {
+			fail(err)
+		}
+		return
+	}
+
 	view, err := client.GetView()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fail(err)
+	}
+
+	if err := render.Export(os.Stdout, view, format, &render.Options{Padding: 8}); err != nil {
+		fail(err)
+	}
+}
+
+// printOverview prints what every live canvas reports as one JSON document
+// keyed by ID, with views exported in format
+func printOverview(opts canvas.OverviewOptions, format render.Format) {
+	if remoteHost != "" {
+		fmt.Fprintln(os.Stderr, "Error: --all is not supported with --remote")
 		os.Exit(1)
 	}
-	
-	fmt.Print(view)
+
+	snapshots, err := canvas.Overview(context.Background(), opts)
+	if err != nil {
+		fail(err)
+	}
+
+	for _, snap := range snapshots {
+		if snap.View == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := render.Export(&buf, *snap.View, format, &render.Options{Padding: 8}); err != nil {
+			snap.Error = err.Error()
+			continue
+		}
+		view := buf.String()
+		snap.View = &view
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(snapshots)
 }
 
 func cmdKey(args []string) {
@@ -124,16 +378,15 @@ func cmdKey(args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas key <id> <key>")
 		os.Exit(1)
 	}
-	
+
 	id := args[0]
 	key := args[1]
-	client := canvas.NewClient(id)
-	
+	client := newClient(id)
+
 	if err := client.SendKey(key); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err)
 	}
-	
+
 	fmt.Println("OK")
 }
 
@@ -142,59 +395,229 @@ func cmdInput(args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas input <id> <text>")
 		os.Exit(1)
 	}
-	
+
 	id := args[0]
 	text := strings.Join(args[1:], " ")
-	client := canvas.NewClient(id)
-	
+	client := newClient(id)
+
 	if err := client.SendInput(text); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err)
 	}
-	
+
 	fmt.Println("OK")
 }
 
+func cmdCall(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas call <id> <type> [json]")
+		os.Exit(1)
+	}
+
+	id := args[0]
+	var payload any
+	if len(args) == 3 {
+		if !json.Valid([]byte(args[2])) {
+			fmt.Fprintln(os.Stderr, "Error: payload is not valid JSON")
+			os.Exit(1)
+		}
+		payload = json.RawMessage(args[2])
+	}
+	client := newClient(id)
+
+	var result json.RawMessage
+	if err := client.Call(canvas.MessageType(args[1]), payload, &result); err != nil {
+		fail(err)
+	}
+	if result == nil {
+		fmt.Println("OK")
+		return
+	}
+
+	var out bytes.Buffer
+	json.Indent(&out, result, "", "  ")
+	fmt.Println(out.String())
+}
+
 func cmdClose(args []string) {
 	id := getID(args)
-	client := canvas.NewClient(id)
-	
-	if err := client.Close(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	client := newClient(id)
+
+	result, err := client.RequestClose()
+	if err != nil {
+		fail(err)
+	}
+	if !result.Exited {
+		reason := result.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Fprintf(os.Stderr, "Error: canvas '%s' did not exit: %s\n", id, reason)
 		os.Exit(1)
 	}
-	
+
 	fmt.Println("OK")
 }
 
-func cmdList() {
-	socketDir := canvas.DefaultSocketDir()
-	entries, err := os.ReadDir(socketDir)
+func cmdList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print canvases as JSON")
+	parseFlags(fs, args)
+
+	// Drop canvases whose process has exited before listing.
+	canvas.GC()
+
+	canvases, err := canvas.List()
 	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Println("No active canvases")
-			return
+		fail(err)
+	}
+
+	if *asJSON {
+		if canvases == nil {
+			canvases = []canvas.Entry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(canvases)
+		return
+	}
+
+	if len(canvases) == 0 {
+		fmt.Println("No active canvases")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tPID\tAPP\tSTARTED\tPANE\tCWD")
+	for _, c := range canvases {
+		pid, started := "-", "-"
+		if c.PID > 0 {
+			pid = strconv.Itoa(c.PID)
+		}
+		if !c.StartedAt.IsZero() {
+			started = c.StartedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.ID, c.Status, pid, orDash(c.App), started, orDash(c.TmuxPane), orDash(c.Cwd))
+	}
+	tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// runRemote runs a command with the opencode-canvas binary of the remote
+// host
+func runRemote(cmd string, args []string) {
+	// ssh joins the command line with spaces for the remote shell, so each
+	// argument is quoted to arrive unchanged
+	sshArgs := []string{"--", remoteHost, "opencode-canvas", cmd}
+	for _, arg := range args {
+		sshArgs = append(sshArgs, canvas.ShellQuote(arg))
+	}
+	c := exec.Command("ssh", sshArgs...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			os.Exit(exit.ExitCode())
+		}
+		fail(err)
+	}
+}
+
+// cmdPipe forwards stdio to a canvas socket, for use as the remote end of
+// --remote-cmd where ssh -W is unavailable
+func cmdPipe(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas pipe <socket>")
+		os.Exit(1)
+	}
+
+	socket := args[0]
+	if !strings.ContainsRune(socket, os.PathSeparator) {
+		socket = canvas.SocketPath(socket)
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		io.Copy(conn, os.Stdin)
+		conn.(*net.UnixConn).CloseWrite()
+	}()
+	io.Copy(os.Stdout, conn)
+}
+
+func cmdSchema(args []string) {
+	data := canvas.ProtocolSchema()
+	if len(args) > 0 {
+		var ok bool
+		data, ok = canvas.MessageSchema(canvas.MessageType(args[0]))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown message type: %s\n", args[0])
+			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	var out bytes.Buffer
+	json.Indent(&out, data, "", "  ")
+	fmt.Println(out.String())
+}
+
+func cmdConformance(args []string) {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	mutating := fs.Bool("mutating", false, "also run checks with side effects, ending with close")
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	args = parseFlags(fs, args)
+
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas conformance <id|socket|url> [--mutating] [--json]")
 		os.Exit(1)
 	}
-	
-	found := false
-	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), ".sock") {
-			id := strings.TrimSuffix(entry.Name(), ".sock")
-			client := canvas.NewClient(id)
-			status := "dead"
-			if client.Ping() {
-				status = "alive"
+
+	results := conformance.Run(conformance.Client(newClient(args[0])), &conformance.Options{Mutating: *mutating})
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	} else {
+		counts := make(map[conformance.Status]int)
+		for _, r := range results {
+			counts[r.Status]++
+			line := fmt.Sprintf("%-4s  %s", strings.ToUpper(string(r.Status)), r.Name)
+			if r.Details != "" {
+				line += ": " + r.Details
 			}
-			fmt.Printf("%s\t%s\n", id, status)
-			found = true
+			fmt.Println(line)
 		}
+		fmt.Printf("\n%d passed, %d failed, %d skipped\n", counts[conformance.Pass], counts[conformance.Fail], counts[conformance.Skip])
 	}
-	
-	if !found {
-		fmt.Println("No active canvases")
+
+	if !conformance.Passed(results) {
+		os.Exit(1)
+	}
+}
+
+func cmdGC() {
+	removed, err := canvas.GC()
+	if err != nil {
+		fail(err)
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No stale canvases")
+		return
+	}
+	for _, id := range removed {
+		fmt.Printf("Removed %s\n", id)
 	}
 }
 
@@ -203,49 +626,246 @@ func cmdSpawn(args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas spawn <id> <command...>")
 		os.Exit(1)
 	}
-	
+
 	id := args[0]
 	command := args[1:]
-	
+
 	// Check if we're in tmux
 	if os.Getenv("TMUX") == "" {
 		fmt.Fprintln(os.Stderr, "Error: spawn requires tmux session")
 		os.Exit(1)
 	}
-	
+
 	// Build the command with canvas environment
-	cmdStr := fmt.Sprintf("OPENCODE_CANVAS=1 CANVAS_ID=%s %s", 
+	cmdStr := fmt.Sprintf("OPENCODE_CANVAS=1 CANVAS_ID=%s %s",
 		id, strings.Join(command, " "))
-	
+
 	// Spawn in a new tmux pane
 	tmuxArgs := []string{
 		"split-window", "-h",
-		"-p", "50",          // 50% width
+		"-p", "50", // 50% width
 		"-P", "-F", "#{pane_id}",
 		cmdStr,
 	}
-	
+
 	tmux := exec.Command("tmux", tmuxArgs...)
 	output, err := tmux.Output()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error spawning tmux pane: %v\n", err)
 		os.Exit(1)
 	}
-	
+
 	paneID := strings.TrimSpace(string(output))
-	
+
 	// Save pane ID for later reference
-	paneFile := filepath.Join(canvas.DefaultSocketDir(), fmt.Sprintf("%s.pane", id))
+	paneFile := canvas.PanePath(id)
 	os.MkdirAll(canvas.DefaultSocketDir(), 0755)
 	os.WriteFile(paneFile, []byte(paneID), 0644)
-	
+
 	fmt.Printf("Spawned canvas '%s' in pane %s\n", id, paneID)
 }
 
+func cmdStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print stats as JSON")
+	prometheus := fs.Bool("prometheus", false, "print stats in the Prometheus text format")
+	args = parseFlags(fs, args)
+
+	client := newClient(getID(args))
+	stats, err := client.Stats()
+	if err != nil {
+		fail(err)
+	}
+
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(stats)
+		return
+	case *prometheus:
+		stats.WritePrometheus(os.Stdout)
+		return
+	}
+
+	fmt.Printf("Canvas:       %s\n", stats.Canvas)
+	fmt.Printf("Uptime:       %s\n", time.Since(stats.StartedAt).Round(time.Second))
+	fmt.Printf("Connections:  %d open, %d total\n", stats.ActiveConnections, stats.TotalConnections)
+	fmt.Printf("Traffic:      %s in, %s out\n", formatBytes(stats.BytesIn), formatBytes(stats.BytesOut))
+	fmt.Println()
+
+	types := make([]string, 0, len(stats.Messages))
+	for t := range stats.Messages {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tCOUNT\tERRORS\tP50\tP95\tP99")
+	for _, t := range types {
+		ms := stats.Messages[canvas.MessageType(t)]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n", t, ms.Count, ms.Errors,
+			formatLatency(ms, 0.5), formatLatency(ms, 0.95), formatLatency(ms, 0.99))
+	}
+	tw.Flush()
+}
+
+// formatLatency shows a latency quantile as the bucket bound it is within
+func formatLatency(ms canvas.MessageStats, q float64) string {
+	seconds := func(f float64) time.Duration { return time.Duration(f * float64(time.Second)) }
+	v := ms.Quantile(q)
+	if math.IsNaN(v) {
+		return "-"
+	}
+	if bounds := ms.Latency.Bounds; math.IsInf(v, 1) && len(bounds) > 0 {
+		return ">" + seconds(bounds[len(bounds)-1]).String()
+	}
+	return "≤" + seconds(v).String()
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func cmdAudit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	msgType := fs.String("type", "", "only records of this message type")
+	since := fs.Duration("since", 0, "only records newer than this")
+	last := fs.Int("n", 0, "only the last n records")
+	follow := fs.Bool("follow", false, "keep printing new records")
+	asJSON := fs.Bool("json", false, "print records as JSON lines")
+	args = parseFlags(fs, args)
+
+	// A file is read as is; an ID selects that canvas's records from the
+	// file named in its metadata.
+	target := getID(args)
+	path, id := target, ""
+	if !strings.ContainsRune(target, os.PathSeparator) && !strings.HasSuffix(target, ".jsonl") {
+		meta, err := canvas.ReadMetadata(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: no running canvas '%s'; pass its audit log file instead\n", target)
+			os.Exit(exitNotFound)
+		}
+		if meta.AuditLog == "" {
+			fmt.Fprintf(os.Stderr, "Error: canvas '%s' has no audit log; start it with CANVAS_AUDIT_LOG\n", target)
+			os.Exit(exitFailure)
+		}
+		path, id = meta.AuditLog, target
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fail(err)
+	}
+	defer f.Close()
+
+	var cutoff time.Time
+	if *since > 0 {
+		cutoff = time.Now().Add(-*since)
+	}
+
+	reader := bufio.NewReader(f)
+	var pending []byte // a line still being written
+	next := func() (*canvas.AuditRecord, []byte, bool) {
+		for {
+			chunk, err := reader.ReadBytes('\n')
+			pending = append(pending, chunk...)
+			if err == io.EOF {
+				return nil, nil, false
+			}
+			if err != nil {
+				fail(err)
+			}
+			line := pending
+			pending = nil
+
+			var rec canvas.AuditRecord
+			if json.Unmarshal(line, &rec) != nil ||
+				(id != "" && rec.Canvas != id) ||
+				(*msgType != "" && string(rec.Type) != *msgType) ||
+				rec.Time.Before(cutoff) {
+				continue
+			}
+			return &rec, line, true
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	show := func(rec *canvas.AuditRecord, line []byte) {
+		if *asJSON {
+			os.Stdout.Write(line)
+			return
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", rec.Time.Local().Format("2006-01-02 15:04:05"),
+			rec.Canvas, rec.Type, auditPeer(rec), auditResult(rec), orDash(string(rec.Payload)))
+	}
+	if !*asJSON {
+		fmt.Fprintln(tw, "TIME\tCANVAS\tTYPE\tPEER\tRESULT\tPAYLOAD")
+	}
+
+	type entry struct {
+		rec  *canvas.AuditRecord
+		line []byte
+	}
+	var backlog []entry
+	for {
+		rec, line, ok := next()
+		if !ok {
+			break
+		}
+		backlog = append(backlog, entry{rec, line})
+		if *last > 0 && len(backlog) > *last {
+			backlog = backlog[1:]
+		}
+	}
+	for _, e := range backlog {
+		show(e.rec, e.line)
+	}
+	tw.Flush()
+
+	for *follow {
+		rec, line, ok := next()
+		if !ok {
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		show(rec, line)
+		tw.Flush()
+	}
+}
+
+// auditPeer describes who sent an audited request
+func auditPeer(rec *canvas.AuditRecord) string {
+	switch {
+	case rec.PeerUID != nil:
+		return fmt.Sprintf("pid %d uid %d", rec.PeerPID, *rec.PeerUID)
+	case rec.Remote != "":
+		return rec.Remote
+	}
+	return "-"
+}
+
+// auditResult is "ok" or the error of an audited request
+func auditResult(rec *canvas.AuditRecord) string {
+	if rec.Result == canvas.AuditError {
+		return rec.Error
+	}
+	return rec.Result
+}
+
 func cmdPing(args []string) {
 	id := getID(args)
-	client := canvas.NewClient(id)
-	
+	client := newClient(id)
+
 	if client.Ping() {
 		fmt.Println("OK")
 	} else {
@@ -253,3 +873,44 @@ func cmdPing(args []string) {
 		os.Exit(1)
 	}
 }
+
+func cmdScreenshot(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: opencode-canvas screenshot <id> <file.png>")
+		os.Exit(1)
+	}
+
+	id := args[0]
+	path := args[1]
+	client := newClient(id)
+
+	view, err := client.GetView()
+	if err != nil {
+		fail(err)
+	}
+
+	screen := render.Parse(view)
+	if path == "-" {
+		if err := render.PNG(os.Stdout, screen, &render.Options{Padding: 8}); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// os.Exit skips deferred calls, so the file is closed by hand and
+	// removed rather than left half-written.
+	f, err := os.Create(path)
+	if err != nil {
+		fail(err)
+	}
+	err = render.PNG(f, screen, &render.Options{Padding: 8})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		fail(fmt.Errorf("failed to write screenshot: %w", err))
+	}
+
+	fmt.Printf("Saved screenshot of '%s' to %s\n", id, path)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		hint bool
+	}{
+		{"other error", errors.New("disk full"), exitFailure, false},
+		{"not found", fmt.Errorf("%w: connection refused", canvas.ErrCanvasNotFound), exitNotFound, true},
+		{"not supported", &canvas.ProtocolError{Code: canvas.CodeNotSupported, Message: "no KeyHandler"}, exitNotSupported, true},
+		{"timeout", fmt.Errorf("%w: i/o timeout", canvas.ErrTimeout), exitTimeout, true},
+		{"canvas error", &canvas.ProtocolError{Code: canvas.CodeKeyError, Message: "no such key"}, exitCanvasError, false},
+		{"unauthorized", &canvas.ProtocolError{Code: canvas.CodeUnauthorized, Message: "bad token"}, exitCanvasError, true},
+		{"wrapped canvas error", fmt.Errorf("sending key: %w", &canvas.ProtocolError{Code: canvas.CodeInputError}), exitCanvasError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := exitCode(tt.err)
+			if code != tt.code {
+				t.Errorf("code = %d, want %d", code, tt.code)
+			}
+			if (hint != "") != tt.hint {
+				t.Errorf("hint = %q, want one: %v", hint, tt.hint)
+			}
+		})
+	}
+}
@@ -12,16 +12,16 @@ import (
 
 var (
 	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("205"))
-	
+			Bold(true).
+			Foreground(lipgloss.Color("205"))
+
 	counterStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("86")).
-		Padding(1, 2)
-	
+			Bold(true).
+			Foreground(lipgloss.Color("86")).
+			Padding(1, 2)
+
 	helpStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+			Foreground(lipgloss.Color("241"))
 )
 
 type model struct {
@@ -75,24 +75,21 @@ func (m model) View() string {
 	if m.quitting {
 		return "Goodbye!\n"
 	}
-	
+
 	s := titleStyle.Render("🎨 Canvas Counter Example") + "\n\n"
 	s += counterStyle.Render(fmt.Sprintf("Count: %d", m.count)) + "\n\n"
 	s += helpStyle.Render("↑/k/+ increment • ↓/j/- decrement • r reset • q quit")
-	
+
 	return s
 }
 
 func main() {
 	// Create the base model
 	m := newModel()
-	
-	// Wrap with canvas support (auto-detects canvas mode via env vars)
-	wrapped := canvas.Wrap("counter-example", m)
-	
-	// Run the program
-	p := tea.NewProgram(wrapped)
-	if _, err := p.Run(); err != nil {
+
+	// Run with canvas support (auto-detects canvas mode via env vars); the
+	// socket is removed when the program ends
+	if _, err := canvas.Run("counter-example", m); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
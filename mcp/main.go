@@ -2,31 +2,66 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/AlqattanDev/opencode-canvas/canvas"
+	"github.com/AlqattanDev/opencode-canvas/render"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
+	transport := flag.String("transport", transportStdio, "transport to serve on: stdio, sse or http (streamable HTTP)")
+	addr := flag.String("addr", "127.0.0.1:8765", "listen address for the sse and http transports")
+	flag.Parse()
+
+	// Resource subscriptions end with their session
+	subs := newSubscriptions()
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		subs.forget(session.SessionID())
+	})
+
 	// Create MCP server
 	s := server.NewMCPServer(
 		"opencode-canvas",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
 	)
 
-	// Register all canvas tools
+	// Register all canvas tools, resources and prompts
 	registerTools(s)
+	registerResources(s)
+	registerPrompts(s)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Keep resources in sync with live canvases
+	go newCanvasWatcher(s, subs).run(ctx)
 
-	// Start stdio server
-	if err := server.ServeStdio(s); err != nil {
+	var err error
+	if *transport == transportStdio {
+		err = serveStdio(ctx, s, subs)
+	} else {
+		err = serveHTTP(ctx, s, subs, *transport, *addr)
+	}
+	if err != nil && err != io.EOF && ctx.Err() == nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 		os.Exit(1)
 	}
@@ -36,18 +71,33 @@ func registerTools(s *server.MCPServer) {
 	// canvas_list - List active canvases
 	s.AddTool(
 		mcp.NewTool("canvas_list",
-			mcp.WithDescription("List all active canvas TUIs. Returns canvas IDs and their status (alive/dead)."),
+			mcp.WithDescription("List all active canvas TUIs. Returns canvas IDs, their status (alive/dead), PID, app name, working directory, command line, start time, tmux pane and capabilities."),
 		),
 		handleList,
 	)
 
+	// canvas_overview - State and view of every canvas
+	s.AddTool(
+		mcp.NewTool("canvas_overview",
+			mcp.WithDescription("Get the state and view of every live canvas at once, as one JSON document keyed by canvas ID. Use this to see how several cooperating TUIs relate. A canvas that fails to answer has an 'error' entry instead of failing the call."),
+			mcp.WithBoolean("include_view",
+				mcp.Description("Include each canvas's rendered view (default true)"),
+			),
+			mcp.WithString("format",
+				mcp.Description("View format: 'text' (default) without escape codes, or 'ansi'"),
+				mcp.Enum("text", "ansi"),
+			),
+		),
+		handleOverview,
+	)
+
 	// canvas_ping - Check if canvas is responsive
 	s.AddTool(
 		mcp.NewTool("canvas_ping",
 			mcp.WithDescription("Check if a canvas TUI is responsive and accepting connections."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to ping"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to ping"),
 			),
 		),
 		handlePing,
@@ -59,31 +109,62 @@ func registerTools(s *server.MCPServer) {
 			mcp.WithDescription("Get the internal state of a canvas TUI as JSON. Includes mode, cursor position, custom state, and any other state the TUI exposes."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to query"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to query"),
+			),
+			mcp.WithBoolean("include_schema",
+				mcp.Description("Also return the JSON Schema of the custom state, if the TUI publishes one"),
 			),
 		),
 		handleState,
 	)
 
+	// canvas_snapshot - State and view in one request
+	s.AddTool(
+		mcp.NewTool("canvas_snapshot",
+			mcp.WithDescription("Get the state and the plain-text view of a canvas TUI in one call. Both are taken at the same moment, so prefer this over canvas_state followed by canvas_view."),
+			mcp.WithString("id",
+				mcp.Required(),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to query"),
+			),
+		),
+		handleSnapshot,
+	)
+
 	// canvas_view - Get rendered view
 	s.AddTool(
 		mcp.NewTool("canvas_view",
 			mcp.WithDescription("Get the current rendered view of a canvas TUI. Returns the terminal output as the user would see it (may include ANSI codes)."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to query"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to query"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: 'ansi' (default), 'text' without escape codes, or a self-contained 'html' or 'svg' document"),
+				mcp.Enum("ansi", "text", "html", "svg"),
 			),
 		),
 		handleView,
 	)
 
+	// canvas_screenshot - Render the view to PNG
+	s.AddTool(
+		mcp.NewTool("canvas_screenshot",
+			mcp.WithDescription("Capture a PNG screenshot of a canvas TUI, preserving colors, text attributes and box drawing. Returns the image along with the plain-text view."),
+			mcp.WithString("id",
+				mcp.Required(),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to capture"),
+			),
+		),
+		handleScreenshot,
+	)
+
 	// canvas_key - Send a key press
 	s.AddTool(
 		mcp.NewTool("canvas_key",
 			mcp.WithDescription("Send a key press to a canvas TUI. Supported keys: enter, tab, space, backspace, delete, escape, up, down, left, right, home, end, pageup, pagedown, ctrl+c, ctrl+d, ctrl+z, or any single character."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to send key to"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to send key to"),
 			),
 			mcp.WithString("key",
 				mcp.Required(),
@@ -99,7 +180,7 @@ func registerTools(s *server.MCPServer) {
 			mcp.WithDescription("Send text input to a canvas TUI. The text is sent as if the user typed it."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to send input to"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to send input to"),
 			),
 			mcp.WithString("text",
 				mcp.Required(),
@@ -115,7 +196,7 @@ func registerTools(s *server.MCPServer) {
 			mcp.WithDescription("Request a canvas TUI to close gracefully."),
 			mcp.WithString("id",
 				mcp.Required(),
-				mcp.Description("Canvas ID to close"),
+				mcp.Description("Canvas ID, or a tcp://, ws:// or ssh://user@host/id canvas address, to close"),
 			),
 		),
 		handleClose,
@@ -124,39 +205,71 @@ func registerTools(s *server.MCPServer) {
 
 // Tool handlers
 
+// canvasError reports a failed canvas request to the agent, with a hint
+// on how to recover from the common failures
+func canvasError(err error) (*mcp.CallToolResult, error) {
+	var hint string
+	var protoErr *canvas.ProtocolError
+	switch {
+	case errors.Is(err, canvas.ErrCanvasNotFound):
+		hint = "The canvas is not running. Call canvas_list to see the live canvases and their IDs."
+	case errors.Is(err, canvas.ErrNotSupported):
+		hint = "The app does not support this request. canvas_list shows each canvas's capabilities."
+	case errors.Is(err, canvas.ErrTimeout):
+		hint = "The app did not answer in time; it may be busy. Retry, or check it with canvas_ping."
+	case errors.As(err, &protoErr) && protoErr.Code == canvas.CodeInvalidPayload:
+		hint = "Check the arguments against the tool description."
+	}
+	if hint != "" {
+		return mcp.NewToolResultError(fmt.Sprintf("%v\n\n%s", err, hint)), nil
+	}
+	return mcp.NewToolResultError(err.Error()), nil
+}
+
 func handleList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	socketDir := canvas.DefaultSocketDir()
-	entries, err := os.ReadDir(socketDir)
+	// Drop canvases whose process has exited before listing.
+	canvas.GC()
+
+	canvases, err := canvas.List()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return mcp.NewToolResultText("No active canvases"), nil
-		}
-		return nil, fmt.Errorf("failed to read socket directory: %w", err)
+		return nil, err
 	}
 
-	type canvasInfo struct {
-		ID     string `json:"id"`
-		Status string `json:"status"`
+	if len(canvases) == 0 {
+		return mcp.NewToolResultText("No active canvases"), nil
 	}
 
-	var canvases []canvasInfo
-	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), ".sock") {
-			id := strings.TrimSuffix(entry.Name(), ".sock")
-			client := canvas.NewClient(id)
-			status := "dead"
-			if client.Ping() {
-				status = "alive"
-			}
-			canvases = append(canvases, canvasInfo{ID: id, Status: status})
-		}
+	data, _ := json.MarshalIndent(canvases, "", "  ")
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func handleOverview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	includeView := request.GetBool("include_view", true)
+	format, err := render.ParseFormat(request.GetString("format", string(render.FormatText)))
+	if err != nil || (format != render.FormatText && format != render.FormatANSI) {
+		return mcp.NewToolResultError("format must be text or ansi"), nil
 	}
 
-	if len(canvases) == 0 {
+	canvas.GC()
+	snapshots, err := canvas.Overview(ctx, canvas.OverviewOptions{State: true, View: includeView})
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
 		return mcp.NewToolResultText("No active canvases"), nil
 	}
 
-	data, _ := json.MarshalIndent(canvases, "", "  ")
+	for _, snap := range snapshots {
+		if snap.View == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		render.Export(&buf, *snap.View, format, nil)
+		view := buf.String()
+		snap.View = &view
+	}
+
+	data, _ := json.MarshalIndent(snapshots, "", "  ")
 	return mcp.NewToolResultText(string(data)), nil
 }
 
@@ -166,13 +279,24 @@ func handlePing(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTool
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
 	if client.Ping() {
 		return mcp.NewToolResultText(fmt.Sprintf("Canvas '%s' is alive and responsive", id)), nil
 	}
 
+	// Network addresses have no local socket to look for.
+	if strings.Contains(id, "://") {
+		return mcp.NewToolResultText(fmt.Sprintf("Canvas '%s' is not responding", id)), nil
+	}
+
 	// Check if socket exists but not responding
-	socketPath := filepath.Join(canvas.DefaultSocketDir(), id+".sock")
+	socketPath := id
+	if !strings.ContainsRune(id, filepath.Separator) && !strings.HasSuffix(id, ".sock") {
+		socketPath = canvas.SocketPath(id)
+	}
 	if _, err := os.Stat(socketPath); err == nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Canvas '%s' socket exists but is not responding", id)), nil
 	}
@@ -186,13 +310,49 @@ func handleState(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
 	state, err := client.GetState()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get state from canvas '%s': %w", id, err)
+		return canvasError(fmt.Errorf("failed to get state from canvas '%s': %w", id, err))
 	}
 
 	data, _ := json.MarshalIndent(state, "", "  ")
+	if !request.GetBool("include_schema", false) {
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	schema, err := client.GetStateSchema()
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\nNo schema: %v", data, err)), nil
+	}
+	var indented bytes.Buffer
+	json.Indent(&indented, schema, "", "  ")
+	return mcp.NewToolResultText(fmt.Sprintf("%s\n\nSchema of \"custom\":\n%s", data, indented.String())), nil
+}
+
+func handleSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := request.RequireString("id")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := client.Snapshot()
+	if err != nil {
+		return canvasError(fmt.Errorf("failed to get snapshot of canvas '%s': %w", id, err))
+	}
+	if snap.View != nil {
+		view := render.Parse(*snap.View).String()
+		snap.View = &view
+	}
+
+	data, _ := json.MarshalIndent(snap, "", "  ")
 	return mcp.NewToolResultText(string(data)), nil
 }
 
@@ -202,13 +362,50 @@ func handleView(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTool
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
+	format, err := render.ParseFormat(request.GetString("format", string(render.FormatANSI)))
+	if err != nil || format == render.FormatPNG {
+		return mcp.NewToolResultError("format must be one of ansi, text, html or svg"), nil
+	}
+
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
 	view, err := client.GetView()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get view from canvas '%s': %w", id, err)
+		return canvasError(fmt.Errorf("failed to get view from canvas '%s': %w", id, err))
+	}
+
+	var buf bytes.Buffer
+	if err := render.Export(&buf, view, format, &render.Options{Padding: 8}); err != nil {
+		return nil, fmt.Errorf("failed to export view of canvas '%s': %w", id, err)
 	}
 
-	return mcp.NewToolResultText(view), nil
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+func handleScreenshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := request.RequireString("id")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
+	view, err := client.GetView()
+	if err != nil {
+		return canvasError(fmt.Errorf("failed to get view from canvas '%s': %w", id, err))
+	}
+
+	screen := render.Parse(view)
+	var buf bytes.Buffer
+	if err := render.PNG(&buf, screen, &render.Options{Padding: 8}); err != nil {
+		return nil, fmt.Errorf("failed to render screenshot of canvas '%s': %w", id, err)
+	}
+
+	return mcp.NewToolResultImage(screen.String(), base64.StdEncoding.EncodeToString(buf.Bytes()), "image/png"), nil
 }
 
 func handleKey(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -222,9 +419,12 @@ func handleKey(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolR
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
 	if err := client.SendKey(key); err != nil {
-		return nil, fmt.Errorf("failed to send key to canvas '%s': %w", id, err)
+		return canvasError(fmt.Errorf("failed to send key to canvas '%s': %w", id, err))
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Sent key '%s' to canvas '%s'", key, id)), nil
@@ -241,9 +441,12 @@ func handleInput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
 	if err := client.SendInput(text); err != nil {
-		return nil, fmt.Errorf("failed to send input to canvas '%s': %w", id, err)
+		return canvasError(fmt.Errorf("failed to send input to canvas '%s': %w", id, err))
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Sent input to canvas '%s': %s", id, text)), nil
@@ -255,10 +458,17 @@ func handleClose(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 		return nil, err
 	}
 
-	client := canvas.NewClient(id)
-	if err := client.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close canvas '%s': %w", id, err)
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.RequestClose()
+	if err != nil {
+		return canvasError(fmt.Errorf("failed to close canvas '%s': %w", id, err))
+	}
+	if !result.Exited {
+		return mcp.NewToolResultError(fmt.Sprintf("Canvas '%s' refused to close: %s", id, result.Reason)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Requested canvas '%s' to close", id)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Canvas '%s' is exiting", id)), nil
 }
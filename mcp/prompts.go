@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+	"github.com/AlqattanDev/opencode-canvas/render"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func registerPrompts(s *server.MCPServer) {
+	// describe_canvas - Explain what a canvas currently shows
+	s.AddPrompt(
+		mcp.NewPrompt("describe_canvas",
+			mcp.WithPromptDescription("Fetch a canvas TUI's state and view and ask for an explanation of what it shows."),
+			mcp.WithArgument("id",
+				mcp.RequiredArgument(),
+				mcp.ArgumentDescription("Canvas ID to describe"),
+			),
+		),
+		handleDescribePrompt,
+	)
+
+	// reproduce_bug - Drive a canvas to reproduce a reported bug
+	s.AddPrompt(
+		mcp.NewPrompt("reproduce_bug",
+			mcp.WithPromptDescription("Start a guided session that reproduces a bug in a canvas TUI by sending keys and input and checking state after each step."),
+			mcp.WithArgument("id",
+				mcp.RequiredArgument(),
+				mcp.ArgumentDescription("Canvas ID of the TUI with the bug"),
+			),
+			mcp.WithArgument("description",
+				mcp.RequiredArgument(),
+				mcp.ArgumentDescription("What goes wrong"),
+			),
+			mcp.WithArgument("steps",
+				mcp.ArgumentDescription("Known steps to reproduce, if any"),
+			),
+		),
+		handleReproducePrompt,
+	)
+
+	// explore_ui - Map out the screens and modes of a canvas
+	s.AddPrompt(
+		mcp.NewPrompt("explore_ui",
+			mcp.WithPromptDescription("Start a guided session that explores a canvas TUI's screens, modes and key bindings."),
+			mcp.WithArgument("id",
+				mcp.RequiredArgument(),
+				mcp.ArgumentDescription("Canvas ID to explore"),
+			),
+			mcp.WithArgument("goal",
+				mcp.ArgumentDescription("What to focus on, e.g. a feature or screen"),
+			),
+		),
+		handleExplorePrompt,
+	)
+}
+
+// canvasSnapshot fetches a canvas's state and view and formats them for
+// inclusion in a prompt, noting a part the canvas could not provide. The
+// raw ANSI view is returned as well, empty without a view.
+func canvasSnapshot(id string) (snapshot, view string, err error) {
+	client, err := canvas.Dial(id)
+	if err != nil {
+		return "", "", err
+	}
+	snap, err := client.Snapshot()
+	if err == nil && snap.State == nil && snap.View == nil {
+		err = snap.Err()
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get snapshot of canvas '%s': %w", id, err)
+	}
+
+	var b strings.Builder
+	if snap.State != nil {
+		data, _ := json.MarshalIndent(snap.State, "", "  ")
+		fmt.Fprintf(&b, "State of canvas '%s':\n```json\n%s\n```", id, data)
+	} else {
+		fmt.Fprintf(&b, "State of canvas '%s' is unavailable: %s", id, snap.Error)
+	}
+	if snap.View != nil {
+		view = *snap.View
+		fmt.Fprintf(&b, "\n\nCurrent view:\n```\n%s\n```", render.Parse(view).String())
+	} else {
+		fmt.Fprintf(&b, "\n\nThe view is unavailable: %s", snap.Error)
+	}
+	return b.String(), view, nil
+}
+
+func promptID(request mcp.GetPromptRequest) (string, error) {
+	id := request.Params.Arguments["id"]
+	if id == "" {
+		return "", fmt.Errorf("required argument \"id\" not found")
+	}
+	return id, nil
+}
+
+func handleDescribePrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id, err := promptID(request)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, view, err := canvasSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(snapshot)),
+	}
+
+	// Attach a screenshot so colors and layout are visible too.
+	var buf bytes.Buffer
+	if view != "" && render.PNG(&buf, render.Parse(view), &render.Options{Padding: 8}) == nil {
+		messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser,
+			mcp.NewImageContent(base64.StdEncoding.EncodeToString(buf.Bytes()), "image/png")))
+	}
+
+	messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(
+		"Explain what this TUI is showing: which screen and mode it is in, what the "+
+			"highlighted or focused elements are, and what the user can do next. "+
+			"Point out anything in the view that contradicts the state or looks broken.")))
+
+	return mcp.NewGetPromptResult(fmt.Sprintf("Describe canvas '%s'", id), messages), nil
+}
+
+func handleReproducePrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id, err := promptID(request)
+	if err != nil {
+		return nil, err
+	}
+	description := request.Params.Arguments["description"]
+	if description == "" {
+		return nil, fmt.Errorf("required argument \"description\" not found")
+	}
+
+	snapshot, _, err := canvasSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "I want to reproduce a bug in the TUI running as canvas '%s'.\n\n", id)
+	fmt.Fprintf(&b, "Bug: %s\n\n", description)
+	if steps := request.Params.Arguments["steps"]; steps != "" {
+		fmt.Fprintf(&b, "Known steps:\n%s\n\n", steps)
+	}
+	b.WriteString(snapshot)
+	b.WriteString("\n\nUse canvas_key and canvas_input to drive the TUI one step at a time. " +
+		"After every step, call canvas_state and canvas_view and note what changed. " +
+		"Stop once the bug shows up and report the minimal sequence of keys and input " +
+		"that triggers it, the state before and after, and what you expected instead.")
+
+	return mcp.NewGetPromptResult(fmt.Sprintf("Reproduce bug in canvas '%s'", id), []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+	}), nil
+}
+
+func handleExplorePrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id, err := promptID(request)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, _, err := canvasSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explore the TUI running as canvas '%s'.\n\n", id)
+	if goal := request.Params.Arguments["goal"]; goal != "" {
+		fmt.Fprintf(&b, "Focus on: %s\n\n", goal)
+	}
+	b.WriteString(snapshot)
+	b.WriteString("\n\nStarting from this screen, try the key bindings shown in the view and " +
+		"common ones (tab, arrows, enter, escape, ?) with canvas_key, checking canvas_view " +
+		"and canvas_state after each. Avoid keys that quit or destroy data. Summarize the " +
+		"screens and modes you found, how to move between them, and anything confusing or broken.")
+
+	return mcp.NewGetPromptResult(fmt.Sprintf("Explore canvas '%s'", id), []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+	}), nil
+}
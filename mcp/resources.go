@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlqattanDev/opencode-canvas/canvas"
+	"github.com/AlqattanDev/opencode-canvas/render"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// How often the socket directory is rescanned for canvases
+const watchInterval = 2 * time.Second
+
+func registerResources(s *server.MCPServer) {
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate("canvas://{id}/view", "Canvas view",
+			mcp.WithTemplateDescription("Rendered view of a canvas TUI, including ANSI codes"),
+			mcp.WithTemplateMIMEType("text/plain"),
+		),
+		handleReadResource,
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate("canvas://{id}/state", "Canvas state",
+			mcp.WithTemplateDescription("Internal state of a canvas TUI as JSON"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		handleReadResource,
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate("canvas://{id}/view.{format}", "Canvas view export",
+			mcp.WithTemplateDescription("Rendered view of a canvas TUI as text, html or svg"),
+		),
+		handleReadResource,
+	)
+}
+
+// canvasResources returns the concrete resources of a live canvas
+func canvasResources(id string) []server.ServerResource {
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource(viewURI(id), id+" view",
+				mcp.WithResourceDescription(fmt.Sprintf("Rendered view of canvas '%s'", id)),
+				mcp.WithMIMEType("text/plain"),
+			),
+			Handler: handleReadResource,
+		},
+		{
+			Resource: mcp.NewResource(stateURI(id), id+" state",
+				mcp.WithResourceDescription(fmt.Sprintf("State of canvas '%s' as JSON", id)),
+				mcp.WithMIMEType("application/json"),
+			),
+			Handler: handleReadResource,
+		},
+	}
+}
+
+func viewURI(id string) string  { return "canvas://" + id + "/view" }
+func stateURI(id string) string { return "canvas://" + id + "/state" }
+
+// parseCanvasURI splits canvas://<id>/<kind> into its parts
+func parseCanvasURI(uri string) (id, kind string, ok bool) {
+	rest, ok := strings.CutPrefix(uri, "canvas://")
+	if !ok {
+		return "", "", false
+	}
+	id, kind, ok = strings.Cut(rest, "/")
+	return id, kind, ok && id != ""
+}
+
+func handleReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	id, kind, ok := parseCanvasURI(uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid canvas resource URI: %s", uri)
+	}
+
+	client := canvas.NewClient(id)
+	if kind == "state" {
+		state, err := client.GetState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get state from canvas '%s': %w", id, err)
+		}
+		data, _ := json.MarshalIndent(state, "", "  ")
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+
+	format := render.FormatANSI
+	if name, ok := strings.CutPrefix(kind, "view."); ok {
+		f, err := render.ParseFormat(name)
+		if err != nil || f == render.FormatPNG {
+			return nil, fmt.Errorf("unsupported view format: %s", name)
+		}
+		format = f
+	} else if kind != "view" {
+		return nil, fmt.Errorf("unknown canvas resource: %s", kind)
+	}
+
+	view, err := client.GetView()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view from canvas '%s': %w", id, err)
+	}
+	var buf bytes.Buffer
+	if err := render.Export(&buf, view, format, &render.Options{Padding: 8}); err != nil {
+		return nil, fmt.Errorf("failed to export view of canvas '%s': %w", id, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: format.MIMEType(), Text: buf.String()},
+	}, nil
+}
+
+// subscriptions tracks which sessions subscribed to which resource URIs.
+// mcp-go does not implement resources/subscribe, so the transports hand
+// those requests to intercept before the MCP server sees them.
+type subscriptions struct {
+	mu   sync.Mutex
+	uris map[string]map[string]bool // uri → session IDs
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{uris: make(map[string]map[string]bool)}
+}
+
+// intercept handles a resources/subscribe or resources/unsubscribe request
+// from the given session and returns the JSON-RPC response to send. ok is
+// false for any other message.
+func (subs *subscriptions) intercept(sessionID string, message []byte) (response []byte, ok bool) {
+	var req struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil || req.ID == nil {
+		return nil, false
+	}
+
+	subs.mu.Lock()
+	switch mcp.MCPMethod(req.Method) {
+	case methodResourcesSubscribe:
+		if subs.uris[req.Params.URI] == nil {
+			subs.uris[req.Params.URI] = make(map[string]bool)
+		}
+		subs.uris[req.Params.URI][sessionID] = true
+	case methodResourcesUnsubscribe:
+		delete(subs.uris[req.Params.URI], sessionID)
+		if len(subs.uris[req.Params.URI]) == 0 {
+			delete(subs.uris, req.Params.URI)
+		}
+	default:
+		subs.mu.Unlock()
+		return nil, false
+	}
+	subs.mu.Unlock()
+
+	response, _ = json.Marshal(mcp.NewJSONRPCResultResponse(mcp.NewRequestId(req.ID), mcp.EmptyResult{}))
+	return append(response, '\n'), true
+}
+
+// forget drops the subscriptions of a session that has ended
+func (subs *subscriptions) forget(sessionID string) {
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	for uri, sessions := range subs.uris {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(subs.uris, uri)
+		}
+	}
+}
+
+// canvas returns the subscribed URIs of one canvas, whatever the format,
+// with the sessions subscribed to each
+func (subs *subscriptions) canvas(id string) map[string][]string {
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	found := make(map[string][]string)
+	for uri, sessions := range subs.uris {
+		if uriID, _, ok := parseCanvasURI(uri); !ok || uriID != id {
+			continue
+		}
+		for session := range sessions {
+			found[uri] = append(found[uri], session)
+		}
+	}
+	return found
+}
+
+const (
+	methodResourcesSubscribe   mcp.MCPMethod = "resources/subscribe"
+	methodResourcesUnsubscribe mcp.MCPMethod = "resources/unsubscribe"
+)
+
+// canvasWatcher keeps the resource list in sync with the live canvases and
+// turns their MsgUpdated events into resource update notifications
+type canvasWatcher struct {
+	mcp  *server.MCPServer
+	subs *subscriptions
+
+	listed map[string]bool // canvases whose resources are registered, used by scan only
+
+	mu   sync.Mutex
+	live map[string]*stream // event streams being followed, by canvas ID
+}
+
+// stream is the event stream of one canvas
+type stream struct {
+	cancel context.CancelFunc
+}
+
+func newCanvasWatcher(s *server.MCPServer, subs *subscriptions) *canvasWatcher {
+	return &canvasWatcher{mcp: s, subs: subs, listed: make(map[string]bool), live: make(map[string]*stream)}
+}
+
+// run rescans the socket directory until ctx is cancelled
+func (w *canvasWatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		w.scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *canvasWatcher) scan(ctx context.Context) {
+	canvases, _ := canvas.List()
+	alive := make(map[string]bool)
+	for _, info := range canvases {
+		if info.Status == canvas.StatusAlive {
+			alive[info.ID] = true
+		}
+	}
+
+	for id := range alive {
+		if !w.listed[id] {
+			w.listed[id] = true
+			w.mcp.AddResources(canvasResources(id)...)
+		}
+	}
+	for id := range w.listed {
+		if !alive[id] {
+			delete(w.listed, id)
+			w.mcp.DeleteResources(viewURI(id), stateURI(id))
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id := range alive {
+		if w.live[id] != nil {
+			continue
+		}
+		followCtx, cancel := context.WithCancel(ctx)
+		st := &stream{cancel: cancel}
+		w.live[id] = st
+		go w.follow(followCtx, id, st)
+	}
+	for id, st := range w.live {
+		if !alive[id] {
+			st.cancel()
+			delete(w.live, id)
+		}
+	}
+}
+
+// follow forwards update events of one canvas to subscribed sessions. When
+// the stream fails or ends it forgets it, so the next scan tries again.
+func (w *canvasWatcher) follow(ctx context.Context, id string, st *stream) {
+	defer func() {
+		st.cancel()
+		w.mu.Lock()
+		if w.live[id] == st {
+			delete(w.live, id)
+		}
+		w.mu.Unlock()
+	}()
+
+	events, err := canvas.NewClient(id).Subscribe(ctx)
+	if err != nil {
+		// Canvases built before event streams existed can still be read.
+		return
+	}
+	for event := range events {
+		if event.Type != canvas.MsgUpdated {
+			continue
+		}
+		for uri, sessions := range w.subs.canvas(id) {
+			for _, session := range sessions {
+				w.mcp.SendNotificationToSpecificClient(session,
+					string(mcp.MethodNotificationResourceUpdated), map[string]any{"uri": uri})
+			}
+		}
+	}
+}
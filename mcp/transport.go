@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports selectable with --transport
+const (
+	transportStdio = "stdio"
+	transportSSE   = "sse"  // HTTP with server-sent events (/sse and /message)
+	transportHTTP  = "http" // streamable HTTP (/mcp)
+)
+
+// stdioSessionID is the fixed session ID mcp-go gives the stdio client
+const stdioSessionID = "stdio"
+
+// serveStdio runs the MCP server on stdin/stdout, answering resource
+// subscription requests itself
+func serveStdio(ctx context.Context, s *server.MCPServer, subs *subscriptions) error {
+	stdout := &lockedWriter{w: os.Stdout}
+	in, pipe := io.Pipe()
+
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if resp, ok := subs.intercept(stdioSessionID, line); ok {
+					stdout.Write(resp)
+				} else if _, werr := pipe.Write(line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pipe.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return server.NewStdioServer(s).Listen(ctx, in, stdout)
+}
+
+// lockedWriter serializes writes so intercepted responses never interleave
+// with the ones written by the stdio server
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// serveHTTP runs the MCP server over SSE or streamable HTTP on addr until
+// ctx is cancelled. Several clients can share one server this way.
+func serveHTTP(ctx context.Context, s *server.MCPServer, subs *subscriptions, transport, addr string) error {
+	var handler http.Handler
+	switch transport {
+	case transportSSE:
+		sse := server.NewSSEServer(s)
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses to SSE clients travel over their event stream.
+			sessionID := r.URL.Query().Get("sessionId")
+			body, resp, err := interceptHTTP(w, r, subs, sessionID)
+			if err != nil {
+				bodyError(w, err)
+				return
+			}
+			if resp != nil {
+				if err := sse.SendEventToSession(sessionID, json.RawMessage(resp)); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			r.Body = body
+			sse.ServeHTTP(w, r)
+		})
+	case transportHTTP:
+		streamable := server.NewStreamableHTTPServer(s)
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sessionID := r.Header.Get(server.HeaderKeySessionID)
+			body, resp, err := interceptHTTP(w, r, subs, sessionID)
+			if err != nil {
+				bodyError(w, err)
+				return
+			}
+			if resp != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(resp)
+				return
+			}
+			r.Body = body
+			streamable.ServeHTTP(w, r)
+		})
+	default:
+		return fmt.Errorf("unknown transport %q (want stdio, sse or http)", transport)
+	}
+
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "opencode-canvas MCP server listening on %s (%s)\n", addr, transport)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// maxRequestBody bounds the size of a message posted by an HTTP client
+const maxRequestBody = 4 << 20
+
+// interceptHTTP answers a resource subscription request posted by an HTTP
+// client. For any other request it returns a replacement for the consumed
+// body so the request can be passed on. Bodies larger than maxRequestBody
+// are not read to the end.
+func interceptHTTP(w http.ResponseWriter, r *http.Request, subs *subscriptions, sessionID string) (body io.ReadCloser, response []byte, err error) {
+	if r.Method != http.MethodPost {
+		return r.Body, nil, nil
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	r.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	if sessionID != "" {
+		if resp, ok := subs.intercept(sessionID, data); ok {
+			return nil, resp, nil
+		}
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil, nil
+}
+
+// bodyError answers a request whose body could not be read
+func bodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "failed to read request body", http.StatusBadRequest)
+}
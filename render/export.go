@@ -0,0 +1,62 @@
+package render
+
+import (
+	"fmt"
+	"io"
+)
+
+// Format names an export format for a view
+type Format string
+
+const (
+	FormatANSI Format = "ansi" // the view as captured, with escape codes
+	FormatText Format = "text" // plain text without styling
+	FormatHTML Format = "html"
+	FormatSVG  Format = "svg"
+	FormatPNG  Format = "png"
+)
+
+// Formats lists every supported export format
+var Formats = []Format{FormatANSI, FormatText, FormatHTML, FormatSVG, FormatPNG}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (want ansi, text, html, svg or png)", name)
+}
+
+// MIMEType returns the media type of the format's output
+func (f Format) MIMEType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html"
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatPNG:
+		return "image/png"
+	}
+	return "text/plain"
+}
+
+// Export converts an ANSI view into the given format
+func Export(w io.Writer, view string, f Format, opts *Options) error {
+	switch f {
+	case FormatANSI:
+		_, err := io.WriteString(w, view)
+		return err
+	case FormatText:
+		_, err := io.WriteString(w, Parse(view).String()+"\n")
+		return err
+	case FormatHTML:
+		return HTML(w, Parse(view), opts)
+	case FormatSVG:
+		return SVG(w, Parse(view), opts)
+	case FormatPNG:
+		return PNG(w, Parse(view), opts)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestExportText(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, testView, FormatText, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<red> & ┌─┐\nblue 世界\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, testView, FormatHTML, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<span style="color:` + hexColor(testRed()) + `;font-weight:bold">&lt;red&gt;</span>`,
+		` &amp; ┌─┐`,
+		`background-color:` + hexColor(DefaultTheme.ANSI[4]),
+		`世界`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestExportSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, testView, FormatSVG, &Options{Padding: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output must be well-formed XML of the expected size.
+	var svg struct {
+		Width  int `xml:"width,attr"`
+		Height int `xml:"height,attr"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &svg); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+	if w, h := 11*CellWidth+8, 2*CellHeight+8; svg.Width != w || svg.Height != h {
+		t.Errorf("size = %dx%d, want %dx%d", svg.Width, svg.Height, w, h)
+	}
+	for _, want := range []string{"&lt;red&gt;", `font-weight="bold"`, "世界"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestExportANSI(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, testView, FormatANSI, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != testView {
+		t.Errorf("got %q, want the view unchanged", b.String())
+	}
+}
@@ -0,0 +1,247 @@
+// Code generated from DejaVu Sans Mono (Bitstream Vera license); DO NOT EDIT.
+
+package render
+
+// glyphData holds 4-bit coverage bitmaps for the embedded monospace font.
+// Each entry is CellHeight rows of CellWidth hex digits, one digit per
+// pixel, rasterized from DejaVu Sans Mono at 16px.
+var glyphData = map[rune]string{
+	' ':      "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'!':      "00000000000000000000000000000000009900000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc0000000033000000000000000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'"':      "000000000000000000000000000000003c33c300004f44f400004f44f400004f44f400003c33c300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'#':      "00000000000000000000000000000000006405500000f40d700004f01f30044ad47f430ffffffffc000f50d700003f11f30068bf8af8809cfecfecc000f40d700004f01f300008c05f000000000000000000000000000000000000000000000000000000",
+	'$':      "000000000000000000000000120000000048000000017a4000006feeef3003f748042004f148000002fa580000006ffea40000007ccf500000480cd00000480ad004a56a6f80019dffe70000004800000000480000000012000000000000000000000000",
+	'%':      "00000000000000000000000000000000320000000cffa000007c01e500008800b700004f56f2017105cc44ae710017da30001ad616ff8003003f32e500008c008800003f32e6000006ff9000000000000000000000000000000000000000000000000000",
+	'&':      "000000000000000000000000000000001accb00000bf65a00000f800000000ea000000007f30000001dfd100000cb1fa00c83f204f60c84f0008f3e54f4000cfe00df401afb001cfffc8f600024200000000000000000000000000000000000000000000",
+	'\'':     "00000000000000000000000000000000009900000000cc00000000cc00000000cc00000000990000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'(':      "000000000000000000000000014000000008c00000002f400000009e00000000f900000003f400000006f200000008f000000008f000000006f200000003f500000000e9000000008e000000002f4000000008c000000000000000000000000000000000",
+	')':      "000000000000000000000004100000000c8000000004f200000000e9000000009f000000004f300000002f600000000f800000000f800000002f600000005f300000009e00000000e800000004f20000000c800000000000000000000000000000000000",
+	'*':      "0000000000000000000000000000000000660000023088032003d9999d300006ff6000004cddc40005c2882c5000008800000000440000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'+':      "0000000000000000000000000000000000000000000000000000003300000000cc00000000cc00000000cc00003cccffccc33cccffccc30000cc00000000cc00000000cc0000000000000000000000000000000000000000000000000000000000000000",
+	',':      "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004400000000ff00000000ff00000003f600000008e00000000320000000000000000000000000",
+	'-':      "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ffff000000444400000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'.':      "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000008800000000ff00000000ff000000000000000000000000000000000000000000000000000000",
+	'/':      "0000000000000000000000000000000000000c500000006f20000000ea00000006f20000000ea00000004f40000000cc00000004f40000000bd00000002f60000000ae00000002f600000009e00000000750000000000000000000000000000000000000",
+	'0':      "0000000000000000000000000000000018cc810000cf88fb0003f6006f3008f1001f800cf0000fc00cc0cc0cc00cc0ff0cc00cd0210dc009f0000f9006f4004f6000ed22de00003effe30000004400000000000000000000000000000000000000000000",
+	'1':      "0000000000000000000000000000000036ac300000ffef400000404f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000347f743000cfffffc000000000000000000000000000000000000000000000000000",
+	'2':      "000000000000000000000000000000029ccc810008fa8afc000310008f400000004f500000006f30000001fc0000000ce1000000af3000000af30000009f30000006f94444200cffffff8000000000000000000000000000000000000000000000000000",
+	'3':      "000000000000000000000000000000049ccc810008d889fd000000006f400000004f40000001bf10000cffd300000689fa000000004f600000000f800000002f80096315df3009ffffe50000034300000000000000000000000000000000000000000000",
+	'4':      "000000000000000000000000000000000006c60000002ef8000000c8f8000005e0f800001f40f800009c00f80004f200f8000e8000f8000ffffffff0044444fa40000000f800000000f80000000000000000000000000000000000000000000000000000",
+	'5':      "00000000000000000000000000000003ccccc90004fdccc90004f400000004f400000004fbca500004ecaefa00010000af300000002f800000000f800000005f60086315ff100affffc30000144200000000000000000000000000000000000000000000",
+	'6':      "0000000000000000000000000000000005ccc900008fc88e0002f800000007f10000000bc3ac91000cefa9ff100cf7003f800cf0000dc00af0000cc006f2000fa001fc209f40003efff80000003410000000000000000000000000000000000000000000",
+	'7':      "00000000000000000000000000000009cccccc6009ccccdf700000007f10000000ea00000004f50000000ae00000001f900000007f20000000ed00000003f60000000af10000001fa0000000000000000000000000000000000000000000000000000000",
+	'8':      "000000000000000000000000000000003acca20002fd66df2007f3003f7008f0000f8002f7007f20004fddf40001ce88ec1008f1001f800cc0000cc00ce0000ec006f9119f60009ffff90000004400000000000000000000000000000000000000000000",
+	'9':      "000000000000000000000000000000003bcc810002fd68fc000af1006f300cc0000f800cc0000fb00bf0004fc004fa45dfc0006eff6cb00000000f800000006f30008316fc0000ffffa10000034100000000000000000000000000000000000000000000",
+	':':      "00000000000000000000000000000000000000000000000000000000000000004400000000ff00000000ff000000000000000000000000000000000000008800000000ff00000000ff000000000000000000000000000000000000000000000000000000",
+	';':      "00000000000000000000000000000000000000000000000000000000000000004400000000ff00000000ff000000000000000000000000000000000000004400000000ff00000000ff00000003f600000008e00000000320000000000000000000000000",
+	'<':      "00000000000000000000000000000000000000000000000000000000000000000005b4000028efb2005bfe72003efa4000003efa400000005afe8200000017efd200000004a4000000000000000000000000000000000000000000000000000000000000",
+	'=':      "000000000000000000000000000000000000000000000000000000000000000000000014444444414ffffffff4000000000000000000004ffffffff414444444410000000000000000000000000000000000000000000000000000000000000000000000",
+	'>':      "0000000000000000000000000000000000000000000000000000000000004b500000002bfe8200000027efb500000004afe3000004afe30028efa5002dfe7100004a40000000000000000000000000000000000000000000000000000000000000000000",
+	'?':      "000000000000000000000000000000004acca20000fb88ff100040006f400000006f30000003fb0000003fd1000000cd10000000f800000000c6000000000000000000f800000000f8000000000000000000000000000000000000000000000000000000",
+	'@':      "00000000000000000000000000000000000000000006cfd81000ce645db009d10001f31f401785c66d01dd8ef88a07e001f88808a000c88908c000d88c03f607f83f106ffdd80c9000000003f9000000003dfccd20000038861000000000000000000000",
+	'A':      "0000000000000000000000000000000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'B':      "00000000000000000000000000000006cccb710008fcccff2008f0002f9008f0000fb008f0005f7008fccef90008f888cf3008f0000ce008f00008f008f0000af008f4449f9008ffffe80000000000000000000000000000000000000000000000000000",
+	'C':      "0000000000000000000000000000000003accb30005fc88c8001fc00001006f500000009f00000000cf00000000cf00000000bf000000007f300000002f8000000009f7116700009ffff6000000440000000000000000000000000000000000000000000",
+	'D':      "00000000000000000000000000000009cca710000cfccff6000cc001bf200cc0003f700cc0000fc00cc0000dc00cc0000cc00cc0000fc00cc0001f900cc0008f400cd46afa000cfffc600000000000000000000000000000000000000000000000000000",
+	'E':      "00000000000000000000000000000003cccccc9004fdcccc9004f400000004f400000004f400000004ffffff8004fa88884004f400000004f400000004f400000004f744443004ffffffc000000000000000000000000000000000000000000000000000",
+	'F':      "00000000000000000000000000000000cccccc9000fecccc9000f800000000f800000000f800000000ffffff8000fc88884000f800000000f800000000f800000000f800000000f800000000000000000000000000000000000000000000000000000000",
+	'G':      "0000000000000000000000000000000005ccc81000afa88e8004f80001300af10000000ec00000000fc00000000fc008ffc00fc0024dc00ce0000cc007f4000cc001df502ec0001cfffe5000002430000000000000000000000000000000000000000000",
+	'H':      "00000000000000000000000000000009900009900cc0000cc00cc0000cc00cc0000cc00cc0000cc00cffffffc00ce8888ec00cc0000cc00cc0000cc00cc0000cc00cc0000cc00cc0000cc000000000000000000000000000000000000000000000000000",
+	'I':      "00000000000000000000000000000003cccccc3003ccffcc300000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000144dd441004ffffff4000000000000000000000000000000000000000000000000000",
+	'J':      "000000000000000000000000000000000cccc900000cccfc00000000fc00000000fc00000000fc00000000fc00000000fc00000000fc00000000fc00010001f8000e611af5000bffff900000044100000000000000000000000000000000000000000000",
+	'K':      "00000000000000000000000000000009900006c30cc0006f900cc006fa000cc03fa0000cc3fa00000ceff500000cfacf10000ce12fb0000cc007f7000cc000cf200cc0002fc00cc00008f700000000000000000000000000000000000000000000000000",
+	'L':      "00000000000000000000000000000003c600000004f800000004f800000004f800000004f800000004f800000004f800000004f800000004f800000004f800000004fa44444004fffffff000000000000000000000000000000000000000000000000000",
+	'M':      "0000000000000000000000000000003cc1001cc34ff6006ff44fbb00baf44f5f11f5f44f4b66b4f44f46bd64f44f41ff14f44f409904f44f400004f44f400004f44f400004f44f400004f400000000000000000000000000000000000000000000000000",
+	'N':      "00000000000000000000000000000009c50009900cfc000cc00cef200cc00ccc900cc00cc6f10cc00cc0f60cc00cc08d0cc00cc02f3cc00cc00bacc00cc005fdc00cc000efc00cc0008fc000000000000000000000000000000000000000000000000000",
+	'O':      "000000000000000000000000000000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'P':      "00000000000000000000000000000003cccb820004fdccff6004f4001ee004f40009f004f4000af004f4006fc004fffffd3004f744300004f400000004f400000004f400000004f400000000000000000000000000000000000000000000000000000000",
+	'Q':      "000000000000000000000000000000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cd00cf0000fc007f2002f7002fd22df20005ffff500000047f6000000006d10000000000000000000000000000000",
+	'R':      "00000000000000000000000000000009ccc960000cfccdfd000cf0008f600cf0004f800cf0005f700cf445dd100cffffd1000cf007f8000cf0008f200cf0001fa00cf0000af20cf00002fa00000000000000000000000000000000000000000000000000",
+	'S':      "000000000000000000000000000000002accc70002fe88af0009f10001000cc00000000af400000002efea60000017bffd100000007f900000000cc00000000dc00894029f6005effff80000024400000000000000000000000000000000000000000000",
+	'T':      "0000000000000000000000000000006cccccccc66cccffccc60000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'U':      "00000000000000000000000000000009c0000c900cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00bf0000fb008f0000f8004fa11af40006ffff60000004400000000000000000000000000000000000000000000",
+	'V':      "0000000000000000000000000000003c400004c31f900009f10cd0000dc007f2002f7002f6006f2000ea00ae00009e00e900005f23f500000f77f000000bbbb0000006ff60000002ff200000000000000000000000000000000000000000000000000000",
+	'W':      "0000000000000000000000000000009900000099ad000000da8f000000f85f107701f54f40ff04f40f54ef45f00f88bb78f00c8b77b8c009ce44ec9008df00fd8004fc00cf4003f8008f3000000000000000000000000000000000000000000000000000",
+	'X':      "0000000000000000000000000000000b800005c307f4001fb000dd009f10003f63f7000009fcc0000001ff20000003ff5000000dcae100008f31f80002f9008f300ce1000ec05f500005f600000000000000000000000000000000000000000000000000",
+	'Y':      "0000000000000000000000000000004c400004c40ec0000de004f6006f4000be00eb00002f88f2000008ff80000001fe10000000cc00000000cc00000000cc00000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'Z':      "00000000000000000000000000000006ccccccc306cccccff20000004f80000000dd00000008f30000003f80000000dd00000008f30000002f80000000cd00000006f744444108fffffff400000000000000000000000000000000000000000000000000",
+	'[':      "0000000000000000000000014440000004fff0000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004fff000000000000000000000000000000000",
+	'\\':     "0000000000000000000000000000000b7000000008f100000001f8000000008f100000002f600000000ae000000002f600000000ae000000004f400000000cc000000004f400000000cb000000006f200000000840000000000000000000000000000000",
+	']':      "000000000000000000000004441000000fff400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f4000000fff4000000000000000000000000000000000",
+	'^':      "0000000000000000000000000000000001bb1000000affa000007f33f70005f3003f501b600006b1000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'_':      "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000068888888860000000000",
+	'`':      "00000000000000000000003c3000000008d100000000aa0000000004000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'a':      "000000000000000000000000000000000000000000000000000000000000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'b':      "00000000000000000000014100000004f400000004f400000004f400000004f7dfd40004ff64bf2004f8001f9004f4000cc004f4000cc004f4000cc004f6000ea004fe208f4004fafff70000001410000000000000000000000000000000000000000000",
+	'c':      "0000000000000000000000000000000000000000000000000000000000000004bffb30005fb5498000ec00001004f500000004f400000004f400000001fa000000009f7005600009ffff5000000440000000000000000000000000000000000000000000",
+	'd':      "0000000000000000000000000004100000000f400000000f400000000f40003dfd3f4002fb46ef4009f1008f400cc0004f400cc0001f400cc0004f400ae0006f4004f802ef40006fff6f4000014100000000000000000000000000000000000000000000",
+	'e':      "000000000000000000000000000000000000000000000000000000000000001affb30001de64bf2007f2000da00cd4444bc00cffffffc00cc000000009e000000002fb202660003effff5000002420000000000000000000000000000000000000000000",
+	'f':      "00000000000000000000000000342000005fff800000db10000000f8000003ccfecc600144fa44200000f800000000f800000000f800000000f800000000f800000000f800000000f8000000000000000000000000000000000000000000000000000000",
+	'g':      "000000000000000000000000000000000000000000000000000000000000003dfd3c3002fd46df4009f1008f400cc0004f400cc0000f400cc0004f400af1006f4002fa45ef40005efe3f400000004f400040009f0000fecdf60000258620000000000000",
+	'h':      "00000000000000000000014100000004f400000004f400000004f400000004f6bfe60004fe65df1004f6003f4004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8000000000000000000000000000000000000000000000000000",
+	'i':      "00000000000000000000000023000000008c000000006900000000000000009cc900000034ac000000008c000000008c000000008c000000008c000000008c000000008c000008ffffffc000000000000000000000000000000000000000000000000000",
+	'j':      "00000000000000000000000014100000004f400000003c30000000000000006ccc300000247f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000007f200004fff9000001443000000000000000",
+	'k':      "00000000000000000000004200000000f800000000f800000000f800000000f8001b9000f803ea0000f83fa00000fbfd000000ffdf500000fb0bf10000f801fc0000f8004f8000f80009f300000000000000000000000000000000000000000000000000",
+	'l':      "00000000000000000000024441000008fff400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000004f400000000fc441000003eff4000000000000000000000000000000000000000000000000000",
+	'm':      "0000000000000000000000000000000000000000000000000000000000000c8fe5ef600fb4ff5bd00f70cc04f00f40ac04f00f408c04f00f408c04f00f408c04f00f408c04f00f408c04f000000000000000000000000000000000000000000000000000",
+	'n':      "00000000000000000000000000000000000000000000000000000000000003c5bfe60004fe65df1004f6003f4004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8000000000000000000000000000000000000000000000000000",
+	'o':      "000000000000000000000000000000000000000000000000000000000000003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'p':      "00000000000000000000000000000000000000000000000000000000000003c3dfd30004fe64bf2004f8001f9004f4000cc004f1000cc004f4000cc004f6000ea004fe208f4004f6fff60004f014100004f000000004f000000001400000000000000000",
+	'q':      "000000000000000000000000000000000000000000000000000000000000003bfd3c6001fd56ef8007f2006f800be0001f800cc0000f800cc0000f8008f1005f8002fa01df80006fff8f800001420f800000000f800000000f8000000004200000000000",
+	'r':      "000000000000000000000000000000000000000000000000000000000000000c66efc2000fde86a4000ff10000000f900000000f800000000f800000000f800000000f800000000f80000000000000000000000000000000000000000000000000000000",
+	's':      "000000000000000000000000000000000000000000000000000000000000003bffd70000ed54790000f500000000fd500000005effd500000016df100000004f40038201af1003effff60000024300000000000000000000000000000000000000000000",
+	't':      "00000000000000000000000000000000024000000008f000000008f0000009cefccc30034af444100008f000000008f000000008f000000008f000000008f000000004f8441000009fff4000000000000000000000000000000000000000000000000000",
+	'u':      "00000000000000000000000000000000000000000000000000000000000003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4004f8001fb12cf80007fff6f8000024100000000000000000000000000000000000000000000",
+	'v':      "0000000000000000000000000000000000000000000000000000000000000b700007b00ae0000ea003f3003f3000e900ae00009f00f900003f55f300000daad0000007ff70000002ff200000000000000000000000000000000000000000000000000000",
+	'w':      "00000000000000000000000000000000000000000000000000000000000099000000998e000000e85f103301f51f50dd05f10d81ef18d009c6aa6c9006fa55af6002ff11ff2000ec00ce0000000000000000000000000000000000000000000000000000",
+	'x':      "00000000000000000000000000000000000000000000000000000000000008b1001b8001f900af10004f55f4000008ff80000000ff00000009ff9000005f44f50003f8009f301dd0000dd100000000000000000000000000000000000000000000000000",
+	'y':      "0000000000000000000000000000000000000000000000000000000000000a800006c108f1000db002f6003f5000bc009f00006f20e900000f85f2000009eac0000002ff60000000df10000000ea00000007f4000006efa0000002430000000000000000",
+	'z':      "00000000000000000000000000000000000000000000000000000000000000cccccc30008888cf30000003f90000001dc0000000be10000008f30000005f60000002fc44441004ffffff4000000000000000000000000000000000000000000000000000",
+	'{':      "00000000000000000000000000140000002eff0000009f20000000cc00000000cc00000000cc00000000dc00000049f5000000cfd100000001ea00000000cc00000000cc00000000cc00000000ad000000006fa800000005880000000000000000000000",
+	'|':      "0000000000000000000000003300000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc000000009900000000000000",
+	'}':      "00000000000000000000004100000000ffe200000002f800000000cc00000000cc00000000cc00000000cc000000005f940000001dfc000000ae10000000cc00000000cc00000000cc00000000d90000008af60000008840000000000000000000000000",
+	'~':      "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001affe844b44b547effb1100000210000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00a1': "00000000000000000000000000000000000000000000000000000000000000009900000000cc0000000033000000000000000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc000000000000000000000000",
+	'\u00a2': "0000000000000000000000000000000000012000000004800000000480000003bffc40003fb7a76000ce04800000f804800004f504800001f804800000ec048000005f7483400006ffff60000007a0000000048000000002400000000000000000000000",
+	'\u00a3': "00000000000000000000000000000000005ccc700005fb57a0000bf00000000dc00000000fc00000000fc0000008fffff800000fc00000000fc00000000fc00000034fd444300cffffffc000000000000000000000000000000000000000000000000000",
+	'\u00a4': "000000000000000000000000000000000000000000000000000000000000004000013000da9b6d60001f85da00005b002e00005b002e00001f95dc0000d78a6d900020000130000000000000000000000000000000000000000000000000000000000000",
+	'\u00a5': "0000000000000000000000000000004c400004c40ec0000dd004f6006f4000ce00eb00046f88f640088bffb8800001fe10000ffffffff00000cc00000000cc00000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'\u00a6': "00000000000000000000000000000000003300000000cc00000000cc00000000cc00000000cc00000000cc00000000990000000000000000003300000000cc00000000cc00000000cc00000000cc00000000cc0000000099000000000000000000000000",
+	'\u00a7': "0000000000000000000000000000000008ccb400008f64760000cc000000007f800000006efe400002f319f80004f1006f3001ed200f40001cf8ad0000005ff300000001eb00001000dc0000cd9df5000026882000000000000000000000000000000000",
+	'\u00a8': "000000000000000000000000000000004f44f4000028228200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00a9': "0000000000000000000000000000000000000000001688610003d8448d301e35bc83e1865d205068c0c500001cc0c400000ca3a800003a4b1cdcd0b406b1001b60005dccd500000000000000000000000000000000000000000000000000000000000000",
+	'\u00aa': "000000000000000000000000000000002bcc8000002502d600000488ca00005f88cc0000c800ac00008d8afc0000068646000048888600002444430000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00ab': "00000000000000000000000000000000000000000000000000000000000000001000000003b00940004f60ad2006f61cd1000ec03f400001db16f600001cb05f300000600330000000000000000000000000000000000000000000000000000000000000",
+	'\u00ac': "000000000000000000000000000000000000000000000000000000000000000000000000000000003cccccccc32888888af400000004f400000004f400000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00ad': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ffff000000444400000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00ae': "0000000000000000000000000000000000000000001688610003d8448d301e388843e1860f02f168c00f15f11cc00f8f500ca30f05d03a4b0c0094b406b1001b60005dccd500000000000000000000000000000000000000000000000000000000000000",
+	'\u00af': "000000000000000000000000000000004ffff4000014444100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b0': "0000000000000000000000000000000005cc5000004f55f3000088008800006d11d5000009ff9000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b1': "0000000000000000000000000000000000000000000000000000003300000000cc00000000cc00002888ee88823cccffccc30000cc00000000cc0000000033000014444444414ffffffff400000000000000000000000000000000000000000000000000",
+	'\u00b2': "000000000000000000000000000000002bcc5000002305f000000002f00000001d60000001d60000001d600000003cccc3000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b3': "000000000000000000000000000000000ccc8000000203f300000045f2000000ce9000000000e600001202f500003cfc80000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b4': "00000000000000000000000003c30000001d80000000aa0000000040000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b5': "00000000000000000000000000000000000000000000000000000000000003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4003f8004fb11bf9204faffcbf804f033014004f000000004f000000001400000000000000000",
+	'\u00b6': "0000000000000000000000000000000028cccc3003fffd4d400dfffc0c400ffffc0c400efffc0c4006fffc0c40005cfc0c4000008c0c4000008c0c4000008c0c4000008c0c4000008c0c4000008c0c400000460620000000000000000000000000000000",
+	'\u00b7': "000000000000000000000000000000000000000000000000000000000000000000000000000000000000cc00000000ff00000000cc0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00b8': "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001d200000000a8000000cff400000000000000000000000",
+	'\u00b9': "0000000000000000000000000000000028c900000026ac000000008c000000008c000000008c000000008c0000000cccc3000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00ba': "0000000000000000000000000000000008cc8000008d22d80000d6006d0000f4004f0000c8009c00004f77f40000028820000068888400003444420000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u00bb': "000000000000000000000000000000000000000000000000000000000000000001000004a00b300002da06f400001dd16f600004f30bf0006f61bd1003f50bd1000330060000000000000000000000000000000000000000000000000000000000000000",
+	'\u00bc': "000000000000000000003cfc000000128c000000008c000000008c000000008c00000004ad41000008888359900159dea6207d95205600000002ec0000000b6c000000774c000002e47d300003ccdf900000004c00000000000000000000000000000000",
+	'\u00bd': "000000000000000000003cfc000000128c000000008c000000008c000000008c00000004ad41000008888359900159dea6207d955884000000865e8000000008b00000001f30000001d60000001d60000000becc90000000000000000000000000000000",
+	'\u00be': "000000000000000000000bcdc100000000d800000037d200000069e3000000009900000645e7000008ca6159900159dea6207d95205600000002ec0000000b6c000000774c000002e47d300003ccdf900000004c00000000000000000000000000000000",
+	'\u00bf': "00000000000000000000000000000000000000000000000000000000000000006c000000008f00000000240000000048000000008f00000000ce0000000af50000008f60000002f800000004f500010002fd447f00005effe80000000000000000000000",
+	'\u00c0': "0006b000000000b9000000001400000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c1': "00000b600000009b000000004100000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c2': "0001cc1000000d66d00000130031000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c3': "001ba26600008a6ef30000000000000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c4': "0028228200004f44f40000000000000000cc00000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c5': "0003cc3000000f44f000002d00d200000cbbc0000005ff5000000adda000000f88f000003f34f300009f00f90000eb00be0002f9449f2007ffffff700dd0000dd01f900009f16f400004f600000000000000000000000000000000000000000000000000",
+	'\u00c6': "0000000000000000000000000000000008ccccc3000fdfecc3003f2c8000008e0c800000c90c800001f50cfff005f10cc88009e44d80000effff80002f500c80006f000ca442bc000cfff800000000000000000000000000000000000000000000000000",
+	'\u00c7': "0000000000000000000000000000000003accb30005fc88c8001fc00001006f500000009f00000000cf00000000cf00000000bf000000007f300000002f8000000009f7116700009ffff60000005f000000000d4000000ffe10000000000000000000000",
+	'\u00c8': "0003b100000000ab0000000004100003cccccc9004fdcccc9004f400000004f400000004f400000004ffffff8004fa88884004f400000004f400000004f400000004f744443004ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00c9': "000009800000007d1000000041000003cccccc9004fdcccc9004f400000004f400000004f400000004ffffff8004fa88884004f400000004f400000004f400000004f744443004ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00ca': "0001bb3000000a94e100001400320003cccccc9004fdcccc9004f400000004f400000004f400000004ffffff8004fa88884004f400000004f400000004f400000004f744443004ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00cb': "0028408400004f80f800000000000003cccccc9004fdcccc9004f400000004f400000004f400000004ffffff8004fa88884004f400000004f400000004f400000004f744443004ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00cc': "0006b000000000b90000000014000003cccccc3003ccffcc300000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000144dd441004ffffff4000000000000000000000000000000000000000000000000000",
+	'\u00cd': "00000b600000009b0000000041000003cccccc3003ccffcc300000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000144dd441004ffffff4000000000000000000000000000000000000000000000000000",
+	'\u00ce': "0001cc1000000d66d000001300310003cccccc3003ccffcc300000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000144dd441004ffffff4000000000000000000000000000000000000000000000000000",
+	'\u00cf': "0028228200004f44f400000000000003cccccc3003ccffcc300000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000144dd441004ffffff4000000000000000000000000000000000000000000000000000",
+	'\u00d0': "00000000000000000000000000000009cca710000cfccff4000cc001bf100cc0003f700cc0000fb09ffcc00dc06ee8800cc00cc0000fc00cc0001f900cc0008f300cd46af9000cfffc600000000000000000000000000000000000000000000000000000",
+	'\u00d1': "001bb37600008a6ee300000000000009c50009900cfc000cc00cef200cc00ccc900cc00cc6f10cc00cc0f60cc00cc08d0cc00cc02f3cc00cc00bacc00cc005fdc00cc000efc00cc0008fc000000000000000000000000000000000000000000000000000",
+	'\u00d2': "0006b000000000b900000000140000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'\u00d3': "00000b600000009b00000000410000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'\u00d4': "0001cc1000000d66d0000013003100001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'\u00d5': "001ba26600008a6ef3000000000000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'\u00d6': "0028228200004f44f4000000000000001acca10000df88fd0006f5005f600bf0000fb00cc0000cc00fc0000cf00fc0000cf00cc0000cc00cf0000fc007f2002f7002fd22df20005ffff50000004400000000000000000000000000000000000000000000",
+	'\u00d7': "000000000000000000000000000000000000000000000000000000000000015000051005f6006f50006f66f6000006ff60000006ff6000006f66f60005f6006f500150000510000000000000000000000000000000000000000000000000000000000000",
+	'\u00d8': "000000000000000000000000000000001acca1b600df88fec006f5005f600bf000dfb00cd008bcc00fc03f1cf00fc1e40cf00dc9900cc00cfd100fc009f4002f700bfb22df207d6ffff50032004400000000000000000000000000000000000000000000",
+	'\u00d9': "0006b000000000b90000000014000009c0000c900cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00bf0000fb008f0000f8004fa11af40006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00da': "00000b600000009b0000000041000009c0000c900cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00bf0000fb008f0000f8004fa11af40006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00db': "0001cc1000000d66d000001300310009c0000c900cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00bf0000fb008f0000f8004fa11af40006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00dc': "0028228200004f44f400000000000009c0000c900cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00cf0000fc00bf0000fb008f0000f8004fa11af40006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00dd': "00000b600000009b000000004100004c400004c40ec0000de004f6006f4000be00eb00002f88f2000008ff80000001fe10000000cc00000000cc00000000cc00000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'\u00de': "00000000000000000000000000000003c300000004f400000004fa88830004fdccff9004f4001cf204f40008f404f40008f404f7446ff004fffffc3004f742000004f400000004f400000000000000000000000000000000000000000000000000000000",
+	'\u00df': "000000000000000000000000220000006fffe30002fa11be0004f3005f3004f01cc61004f08e000004f0cd000004f05fb10004f005ef3004f0001cf004f00004f304f2301bf004f4ffff6000001440000000000000000000000000000000000000000000",
+	'\u00e0': "00000000000000000000003c3000000008d100000000aa00000000040000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e1': "00000000000000000000000003c30000001d80000000aa00000000400000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e2': "000000000000000000000000aa00000007dd7000002e22e2000022002200019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e3': "000000000000000000000006404400006dd9b70000661ab1000000000000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e4': "000000000000000000000000000000004f44f40000282282000000000000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e5': "00000000000006ff6000001f11f100002e00e200000aeea0000000330000019dffb30004c745df100000001f600016888f8003feb88f800ae1000f800cc0005f800af304df8001dfff6f8000034000000000000000000000000000000000000000000000",
+	'\u00e6': "0000000000000000000000000000000000000000000000000000000000000aff96ff900a55ff66f300009d00c80003ad44d809fffffff85f408c00008c00ac00006f21ef30351dffb8fff500330024100000000000000000000000000000000000000000",
+	'\u00e7': "0000000000000000000000000000000000000000000000000000000000000004bffb30005fb5498000ec00001004f500000004f400000004f400000001fa000000009f7005600009ffff50000005f000000000d4000000ffe20000000000000000000000",
+	'\u00e8': "00000000000000000000003c4000000006f1000000009c00000000041000001affb30001de64bf2007f2000da00cd4444bc00cffffffc00cc000000009e000000002fb202660003effff5000002420000000000000000000000000000000000000000000",
+	'\u00e9': "00000000000000000000000001c50000000ca00000008c00000000410000001affb30001de64bf2007f2000da00cd4444bc00cffffffc00cc000000009e000000002fb202660003effff5000002420000000000000000000000000000000000000000000",
+	'\u00ea': "0000000000000000000000009b00000005eb8000001f31e3000023002200001affb30001de64bf2007f2000da00cd4444bc00cffffffc00cc000000009e000000002fb202660003effff5000002420000000000000000000000000000000000000000000",
+	'\u00eb': "000000000000000000000000000000004f44f80000282284000000000000001affb30001de64bf2007f2000da00cd4444bc00cffffffc00cc000000009e000000002fb202660003effff5000002420000000000000000000000000000000000000000000",
+	'\u00ec': "00000000000000000000003c3000000008d100000000aa00000000040000009cc900000034ac000000008c000000008c000000008c000000008c000000008c000000008c000008ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00ed': "00000000000000000000000003c30000001d80000000aa00000000400000009cc900000034ac000000008c000000008c000000008c000000008c000000008c000000008c000008ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00ee': "000000000000000000000000aa00000007dd7000002e22e2000022002200009cc900000034ac000000008c000000008c000000008c000000008c000000008c000000008c000008ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00ef': "000000000000000000000000000000004f80f80000284084000000000000009cc900000034ac000000008c000000008c000000008c000000008c000000008c000000008c000008ffffffc000000000000000000000000000000000000000000000000000",
+	'\u00f0': "000000000000000000000024100000001dd377000028ff610000964f900000058df50000afccee0006f7004f600af0000fa00cc0000cc00cc0000cc009f1001f9002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f1': "000000000000000000000006404400006dd9b70000661ab100000000000003c5bfe60004fe65df1004f6003f4004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8000000000000000000000000000000000000000000000000000",
+	'\u00f2': "00000000000000000000003c3000000008d100000000aa00000000040000003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f3': "00000000000000000000000003c30000001d80000000aa00000000400000003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f4': "000000000000000000000000aa00000007dd7000002e22e2000022002200003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f5': "000000000000000000000006404400006dd9b70000661ab1000000000000003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f6': "000000000000000000000000000000004f44f40000282282000000000000003bffb30001fd55df1007f2002f700be0000eb00cc0000cc00cd0000dc008f1001f8002fa11af20006ffff60000004400000000000000000000000000000000000000000000",
+	'\u00f7': "0000000000000000000000000000000000000000000000000000000000000000cc00000000ff000000000000003cccccccc33cccccccc300000000000000ff00000000cc0000000000000000000000000000000000000000000000000000000000000000",
+	'\u00f8': "000000000000000000000000000000000000000000000000000000000040003bffb6e201fd55df4007f201df700bd00a9eb00cc08b0cc00cd6d10dc008fe201f8004fb11af201d9ffff60037004400000000000000000000000000000000000000000000",
+	'\u00f9': "00000000000000000000003c3000000008d100000000aa0000000004000003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4004f8001fb12cf80007fff6f8000024100000000000000000000000000000000000000000000",
+	'\u00fa': "00000000000000000000000003c30000001d80000000aa0000000040000003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4004f8001fb12cf80007fff6f8000024100000000000000000000000000000000000000000000",
+	'\u00fb': "000000000000000000000000aa00000007dd7000002e22e200002200220003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4004f8001fb12cf80007fff6f8000024100000000000000000000000000000000000000000000",
+	'\u00fc': "000000000000000000000000000000004f44f4000028228200000000000003c3000c6004f4000f8004f4000f8004f4000f8004f4000f8004f4000f8004f4004f8001fb12cf80007fff6f8000024100000000000000000000000000000000000000000000",
+	'\u00fd': "00000000000000000000000003c30000001d80000000aa000000004000000a800006c108f1000db002f6003f5000bc009f00006f20e900000f85f2000009eac0000002ff60000000df10000000ea00000007f4000006efa0000002430000000000000000",
+	'\u00fe': "00000000000000000000014000000004f000000004f000000004f000000004f3dfd30004fe64bf2004f8001f9004f4000cc004f1000cc004f4000cc004f6000ea004fe208f4004f6fff60004f014100004f000000004f000000001400000000000000000",
+	'\u00ff': "000000000000000000000000000000004f44f400002822820000000000000a800006c108f1000db002f6003f5000bc009f00006f20e900000f85f2000009eac0000002ff60000000df10000000ea00000007f4000006efa0000002430000000000000000",
+	'\u03bb': "00000000000000000000003000000000ff9000000007f500000000eb000000009f10000000ef70000005fed000000cd6f300004f61f90000be00af0002f6004f500af0000ea01f800007f100000000000000000000000000000000000000000000000000",
+	'\u2013': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000cffffffffc344444444300000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2014': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000cffffffffc344444444300000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2018': "00000000000000000000000003200000001f600000008f20000001fe00000004fc00000002860000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2019': "00000000000000000000000024200000008f800000008f70000000bf10000000f800000001810000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u201c': "000000000000000000000003200410001f706f00008f30ec0001fe06f80004fc08f8000286048400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u201d': "000000000000000000000024203410008f80cf40008f70df2000bf11fa0000f805f2000181046000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2022': "0000000000000000000000000000000000000000000000000000000000000000330000000affa000004ffff400004ffff400000dffd000000044000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2026': "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000028608806824fc0ff0cf44fc0ff0cf400000000000000000000000000000000000000000000000000",
+	'\u2030': "00000000000000000000000000000000400000003fce200000b507900000c506a001405fce33ad700257db50002ad720000036860048402e8b95e8d78900fb504c6c03e9907b09fe31cfc200000000000000000000000000000000000000000000000000",
+	'\u2039': "0000000000000000000000000000000000000000000000000000000000000000000000000006800000006f4000000af30000000f8000000003f9000000003e70000000015000000000000000000000000000000000000000000000000000000000000000",
+	'\u203a': "000000000000000000000000000000000000000000000000000000000000000100000000086000000003f7000000003fa000000008f30000009f30000007e300000005100000000000000000000000000000000000000000000000000000000000000000",
+	'\u20ac': "0000000000000000000000000000000001acca30001de88c80008f20001000fa00000029fc8881004afa88700004f40000004dfecb000000f900000000be000000004fa116700005ffff6000000440000000000000000000000000000000000000000000",
+	'\u2122': "0000000000000000000000000000009cc69907c00840ce4cf00840c6e6f00840c450f006309300c0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2190': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000a50000000ad10000004ffffffff40ad444444100a60000000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2191': "00000000000000000000000000000000000000000000000000000000000000005500000006ff6000005dddd5000011cc11000000cc00000000cc00000000cc00000000cc00000000cc000000000000000000000000000000000000000000000000000000",
+	'\u2192': "000000000000000000000000000000000000000000000000000000000000000000000000000000000000005a000000001da04ffffffff41444444da00000006a000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2193': "00000000000000000000000000000000000000000000000000000000000000006600000000cc00000000cc00000000cc00000000cc00000000cc0000005acca500001dffd1000001dd100000000000000000000000000000000000000000000000000000",
+	'\u2194': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000a5005a000ad1001da04ffffffff40ad4444da000a6006a000000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2195': "00000000000000000000000000000000000000000000000000000000000000005500000006ff6000005dddd5000011cc11000000cc00000000cc0000005acca500001dffd1000001dd100000000000000000000000000000000000000000000000000000",
+	'\u21b5': "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000c300000000f400a60000f40ad44444f44ffffffff40ad100000000a500000000000000000000000000000000000000000000000000000000",
+	'\u2202': "00000000000000000000000000000000000000000006884000000abdf6000000008f100000002f400004840f80008f9e9f5001f700df3004f1008f0007f000ea0003f20af200009fde300000024000000000000000000000000000000000000000000000",
+	'\u221e': "000000000000000000000000000000000000000000000000000000000000000000000006cb11bc603f5acca5f28900ee00988800dc00883e18dd62e309fe33ef900000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2248': "000000000000000000000000000000000000000000000000000000000000000000000001785000223fdcfe9af3340017cb40029b7100333fccefccf323000688200000000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2260': "0000000000000000000000000000000000000000000000000000000005300000003f80144444de414ffffffff400007f30000004f600004ffffffff414ed44444109f3000000035000000000000000000000000000000000000000000000000000000000",
+	'\u2261': "00000000000000000000000000000000000000000000000000000000000028888888823cccccccc300000000003cccccccc33cccccccc300000000003cccccccc32888888882000000000000000000000000000000000000000000000000000000000000",
+	'\u2264': "0000000000000000000000000000000000000000000000000000000000000000000052000016bff4027bffb7204ffa5000002affd7300000039efea200000037d414444444414ffffffff400000000000000000000000000000000000000000000000000",
+	'\u2265': "00000000000000000000000000000000000000000000000000000000000025000000004ffb610000027bffb720000005aff400037dffa22aefe930004d7300000014444444414ffffffff400000000000000000000000000000000000000000000000000",
+	'\u2318': "000000000000000000000000000000000000000001410014201e8d00d8e149094490940dceddecd00008448000014a77a4101e8caac8e1490a44a0941ccd00dcc10040000400000000000000000000000000000000000000000000000000000000000000",
+	'\u25a0': "000000000000000000000000000000000000000000000000000000000000cffffffffccffffffffccffffffffccffffffffccffffffffccffffffffccffffffffccffffffffccffffffffc68888888860000000000000000000000000000000000000000",
+	'\u25a1': "000000000000000000000000000000000000000000000000000000000000cffffffffcc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc00000000cc44444444c68888888860000000000000000000000000000000000000000",
+	'\u25b2': "00000000000000000000000000000000000000000000000000000000000000004400000000cc00000004ff4000000cffc000004ffff40000cffffc0004ffffff400cffffffc04ffffffff458888888850000000000000000000000000000000000000000",
+	'\u25b6': "0000000000000000000000000000000000000000000000000000000000008400000000cfc4000000cfffc40000cfffffc400cfffffffc4cfffffff81cfffff8100cfff810000cf8100000051000000000000000000000000000000000000000000000000",
+	'\u25bc': "0000000000000000000000000000000000000000000000000000000000008ffffffff81ffffffff108ffffff8001ffffff10008ffff800001ffff1000008ff80000001ff1000000088000000001100000000000000000000000000000000000000000000",
+	'\u25c0': "00000000000000000000000000000000000000000000000000000000000000000000480000004cfc00004cfffc004cfffffc4cfffffffc18fffffffc0018fffffc000018fffc00000018fc00000000150000000000000000000000000000000000000000",
+	'\u25c6': "00000000000000000000000000000000000000000000000000000000000000006600000006ff6000006ffff60006ffffff606ffffffff63ffffffff303ffffff30003ffff3000003ff300000003300000000000000000000000000000000000000000000",
+	'\u25c7': "00000000000000000000000000000000000000000000000000000000000000006600000006ff6000006f33f60006f3003f606f300003f63f600006f303f6006f30003f66f3000003ff300000003300000000000000000000000000000000000000000000",
+	'\u25cb': "000000000000000000000000000000000000000000000000000000000000004bffb30006e6006e604e100001e4a60000006ac40000004cc40000004c79000000971f500006f103ea55ae300006aa60000000000000000000000000000000000000000000",
+	'\u25cf': "000000000000000000000000000000000000000000000000000000000000004bffb30006ffffff604ffffffff4affffffffacffffffffccffffffffc7ffffffff71ffffffff103effffe300006aa60000000000000000000000000000000000000000000",
+	'\u2605': "000000000000000000000000000000000000000000000000000000000000000000000000005500000000aa00001444ff444106ffffff60003effe300000ffff000003f55f500005100150000000000000000000000000000000000000000000000000000",
+	'\u2606': "00000000000000000000000000000000000000000000000000000000000000000000000000550000000055000014445544410440000440002500520000053350000057437500005100150000000000000000000000000000000000000000000000000000",
+	'\u26a1': "000000000000000000000000000000000000000000000000200000001830000004b10000019c1000004eb00000007cfd9400000009f70000006e30000006b100000056000000042000000000000000000000000000000000000000000000000000000000",
+	'\u2713': "0000000000000000000000000000000000000000000000000000000000000000003e60000001da0000000ad00000005f20000361f5000008fcb0000003ff1000000074000000000000000000000000000000000000000000000000000000000000000000",
+	'\u2714': "0000000000000000000000000000000000000000000000000000000000000000000682000001dfa000001dfd100000bfe1002eb7ff30003ffff500000eff90000008fd100000000000000000000000000000000000000000000000000000000000000000",
+	'\u2717': "000000000000000000000000000000000000000000000000000000000540000c906f700009f4fa000003ffd0000000ef40000009ffa000005facf20001fe12f7000af40041000bb000000001000000000000000000000000000000000000000000000000",
+	'\u2718': "000000000000000000000000000000000000000000000001200291001de30bf901dff305ff5dff3000effff300006fff6000006fff800005fffff6002ffe9fff205ff30cfa001b6001200000000000000000000000000000000000000000000000000000",
+	'\u276f': "00000000000000000000000000000000000000000044200000009ff10000001ef900000005ff40000000bfe10000001ff800000008ff0000001ff8000000bfe0000005ff4000001ff9000000bfe100000044200000000000000000000000000000000000",
+	'\u279c': "0000000000000000000000000000000000000000000000000000000000000000035000000007f600024444df603ffffffff3044444afa0000005fa0000000490000000000000000000000000000000000000000000000000000000000000000000000000",
+}
@@ -0,0 +1,325 @@
+package render
+
+import (
+	"math"
+	"sync"
+)
+
+// Cell dimensions in pixels of the embedded font
+const (
+	CellWidth  = 10
+	CellHeight = 20
+)
+
+var (
+	glyphOnce sync.Once
+	glyphs    map[rune][]uint8
+)
+
+// glyph returns the coverage bitmap for r, or nil if the font lacks it
+func glyph(r rune) []uint8 {
+	glyphOnce.Do(func() {
+		glyphs = make(map[rune][]uint8, len(glyphData))
+		for r, hex := range glyphData {
+			bitmap := make([]uint8, len(hex))
+			for i := 0; i < len(hex); i++ {
+				v := hex[i] - '0'
+				if hex[i] >= 'a' {
+					v = hex[i] - 'a' + 10
+				}
+				bitmap[i] = v * 0x11
+			}
+			glyphs[r] = bitmap
+		}
+	})
+	return glyphs[r]
+}
+
+// drawGlyph draws a font glyph, or a placeholder box if the font has none
+func drawGlyph(c *cellPainter, r rune, st Style) {
+	bitmap := glyph(r)
+	if bitmap == nil {
+		// Outline box, like a terminal's missing-glyph "tofu".
+		c.fill(2, 4, c.w-4, 1, 0xff)
+		c.fill(2, CellHeight-5, c.w-4, 1, 0xff)
+		c.fill(2, 4, 1, CellHeight-8, 0xff)
+		c.fill(c.w-3, 4, 1, CellHeight-8, 0xff)
+		return
+	}
+
+	// Wide characters are centered in their two cells.
+	dx := (c.w - CellWidth) / 2
+	for y := 0; y < CellHeight; y++ {
+		shift := 0
+		if st.Italic {
+			shift = (CellHeight*3/4 - y) / 5
+		}
+		for x := 0; x < CellWidth; x++ {
+			alpha := bitmap[y*CellWidth+x]
+			c.set(dx+x+shift, y, alpha)
+			if st.Bold {
+				c.set(dx+x+shift+1, y, alpha)
+			}
+		}
+	}
+}
+
+// Line weights for box drawing segments
+const (
+	lineNone = iota
+	lineLight
+	lineHeavy
+	lineDouble
+)
+
+// boxSegments lists the up, right, down and left line weights of
+// U+2500..U+257F, four digits per character. Dashed lines are drawn solid
+// and the diagonals (U+2571..U+2573) are handled separately.
+const boxSegments = "" +
+	"0101020210102020010102021010202001010202101020200110021001200220" + // 2500-250F
+	"0011001200210022110012002100220010011002200120021110121021101120" + // 2510-251F
+	"2120221012202220101110122011102120212012102220220111011202110212" + // 2520-252F
+	"0121012202210222110111021201120221012102220122021111111212111212" + // 2530-253F
+	"2111112121212112221111221221221212222122222122220101020210102020" + // 2540-254F
+	"0303303003100130033000130031003313003100330010033001300313103130" + // 2550-255F
+	"3330101330313033031301310333130331013303131331313333011000111001" + // 2560-256F
+	"1100000000000000000110000100001000022000020000200201102001022010" + // 2570-257F
+	""
+
+// drawBox draws U+2500..U+257F box drawing characters as lines so that
+// borders connect across cells
+func drawBox(c *cellPainter, r rune) bool {
+	if r < 0x2500 || r > 0x257f {
+		return false
+	}
+
+	switch r {
+	case '╱':
+		c.diagonal(true)
+		return true
+	case '╲':
+		c.diagonal(false)
+		return true
+	case '╳':
+		c.diagonal(true)
+		c.diagonal(false)
+		return true
+	}
+
+	i := int(r-0x2500) * 4
+	up, right, down, left := boxSegments[i]-'0', boxSegments[i+1]-'0', boxSegments[i+2]-'0', boxSegments[i+3]-'0'
+
+	if r >= '╭' && r <= '╰' {
+		c.arc(up != 0, right != 0, down != 0, left != 0)
+		return true
+	}
+
+	cx, cy := c.w/2, CellHeight/2
+	const gap = 2
+
+	// Light and heavy segments run through the center far enough to meet
+	// the outer line of any double perpendicular.
+	reach := func(a, b uint8) int {
+		if a == lineDouble || b == lineDouble {
+			return gap
+		}
+		if a == lineHeavy || b == lineHeavy {
+			return 1
+		}
+		return 0
+	}
+	// Double lines stop at the inner line of a double perpendicular and
+	// overshoot to meet the far side otherwise.
+	offset := func(perp uint8) int {
+		switch perp {
+		case lineNone:
+			return -gap
+		case lineDouble:
+			return gap
+		}
+		return 0
+	}
+
+	// Light lines are one pixel wide and heavy lines three.
+	thickness := func(weight uint8) int {
+		if weight == lineHeavy {
+			return 3
+		}
+		return 1
+	}
+
+	hr := reach(left, right)
+	vr := reach(up, down)
+	switch up {
+	case lineNone:
+	case lineDouble:
+		c.fill(cx-gap, 0, 1, cy+1-offset(left), 0xff)
+		c.fill(cx+gap, 0, 1, cy+1-offset(right), 0xff)
+	default:
+		t := thickness(up)
+		c.fill(cx-t/2, 0, t, cy+hr+1, 0xff)
+	}
+	switch down {
+	case lineNone:
+	case lineDouble:
+		c.fill(cx-gap, cy+offset(left), 1, CellHeight-cy-offset(left), 0xff)
+		c.fill(cx+gap, cy+offset(right), 1, CellHeight-cy-offset(right), 0xff)
+	default:
+		t := thickness(down)
+		c.fill(cx-t/2, cy-hr, t, CellHeight-cy+hr, 0xff)
+	}
+	switch left {
+	case lineNone:
+	case lineDouble:
+		c.fill(0, cy-gap, cx+1-offset(up), 1, 0xff)
+		c.fill(0, cy+gap, cx+1-offset(down), 1, 0xff)
+	default:
+		t := thickness(left)
+		c.fill(0, cy-t/2, cx+vr+1, t, 0xff)
+	}
+	switch right {
+	case lineNone:
+	case lineDouble:
+		c.fill(cx+offset(up), cy-gap, c.w-cx-offset(up), 1, 0xff)
+		c.fill(cx+offset(down), cy+gap, c.w-cx-offset(down), 1, 0xff)
+	default:
+		t := thickness(right)
+		c.fill(cx-vr, cy-t/2, c.w-cx+vr, t, 0xff)
+	}
+	return true
+}
+
+// arc draws a rounded corner joining the two given sides
+func (c *cellPainter) arc(up, right, down, left bool) {
+	cx, cy := c.w/2, CellHeight/2
+	const radius = 4
+
+	// Center of the quarter circle, toward the connected sides.
+	ax, ay := cx-radius, cy-radius
+	if right {
+		ax = cx + radius
+	}
+	if down {
+		ay = cy + radius
+	}
+
+	if up {
+		c.fill(cx, 0, 1, cy-radius, 0xff)
+	}
+	if down {
+		c.fill(cx, cy+radius, 1, CellHeight-cy-radius, 0xff)
+	}
+	if left {
+		c.fill(0, cy, cx-radius, 1, 0xff)
+	}
+	if right {
+		c.fill(cx+radius, cy, c.w-cx-radius, 1, 0xff)
+	}
+
+	for step := 0; step <= 24; step++ {
+		angle := float64(step) / 24 * math.Pi / 2
+		dx := int(math.Round(radius * math.Cos(angle)))
+		dy := int(math.Round(radius * math.Sin(angle)))
+		x, y := ax+dx, ay+dy
+		if right {
+			x = ax - dx
+		}
+		if down {
+			y = ay - dy
+		}
+		c.set(x, y, 0xff)
+	}
+}
+
+// diagonal draws a line across the cell, rising to the right if rising
+func (c *cellPainter) diagonal(rising bool) {
+	for y := 0; y < CellHeight; y++ {
+		x := y * c.w / CellHeight
+		if rising {
+			x = c.w - 1 - x
+		}
+		c.set(x, y, 0xff)
+	}
+}
+
+// Quadrant bits for U+2596..U+259F
+const (
+	quadUL = 1 << iota
+	quadUR
+	quadLL
+	quadLR
+)
+
+var quadrants = [...]uint8{
+	quadLL,                   // ▖
+	quadLR,                   // ▗
+	quadUL,                   // ▘
+	quadUL | quadLL | quadLR, // ▙
+	quadUL | quadLR,          // ▚
+	quadUL | quadUR | quadLL, // ▛
+	quadUL | quadUR | quadLR, // ▜
+	quadUR,                   // ▝
+	quadUR | quadLL,          // ▞
+	quadUR | quadLL | quadLR, // ▟
+}
+
+// drawBlock draws U+2580..U+259F block elements so that they fill the
+// cell exactly
+func drawBlock(c *cellPainter, r rune) bool {
+	w, h := c.w, CellHeight
+	switch {
+	case r == '▀':
+		c.fill(0, 0, w, h/2, 0xff)
+	case r >= '▁' && r <= '█':
+		n := int(r-'▁') + 1
+		c.fill(0, h-h*n/8, w, h*n/8, 0xff)
+	case r >= '▉' && r <= '▏':
+		n := 8 - int(r-'▉') - 1
+		c.fill(0, 0, w*n/8, h, 0xff)
+	case r == '▐':
+		c.fill(w/2, 0, w-w/2, h, 0xff)
+	case r >= '░' && r <= '▓':
+		c.fill(0, 0, w, h, uint8(0x40*(r-'░'+1)))
+	case r == '▔':
+		c.fill(0, 0, w, h/8, 0xff)
+	case r == '▕':
+		c.fill(w-w/8, 0, w/8, h, 0xff)
+	case r >= '▖' && r <= '▟':
+		q := quadrants[r-'▖']
+		if q&quadUL != 0 {
+			c.fill(0, 0, w/2, h/2, 0xff)
+		}
+		if q&quadUR != 0 {
+			c.fill(w/2, 0, w-w/2, h/2, 0xff)
+		}
+		if q&quadLL != 0 {
+			c.fill(0, h/2, w/2, h-h/2, 0xff)
+		}
+		if q&quadLR != 0 {
+			c.fill(w/2, h/2, w-w/2, h-h/2, 0xff)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// drawBraille draws U+2800..U+28FF braille patterns, commonly used for
+// spinners and sparklines
+func drawBraille(c *cellPainter, r rune) bool {
+	if r < 0x2800 || r > 0x28ff {
+		return false
+	}
+	dots := uint8(r - 0x2800)
+	// Bit order of the eight dots: three rows per column, then the bottom row.
+	positions := [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+	for bit, pos := range positions {
+		if dots&(1<<bit) == 0 {
+			continue
+		}
+		x := c.w/4 - 1 + pos[0]*c.w/2
+		y := CellHeight/8 - 1 + pos[1]*CellHeight/4
+		c.fill(x, y, 2, 2, 0xff)
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// run is a horizontal span of cells sharing one style
+type run struct {
+	col   int // first column
+	cols  int // width in columns
+	text  string
+	style Style
+}
+
+// runs splits a line into spans of identically styled cells
+func runs(line []Cell) []run {
+	var out []run
+	var text strings.Builder
+	for col, cell := range line {
+		if len(out) == 0 || out[len(out)-1].style != cell.Style {
+			if len(out) > 0 {
+				out[len(out)-1].text = text.String()
+				text.Reset()
+			}
+			out = append(out, run{col: col, style: cell.Style})
+		}
+		out[len(out)-1].cols++
+		if cell.Rune != 0 {
+			text.WriteRune(cell.Rune)
+		}
+	}
+	if len(out) > 0 {
+		out[len(out)-1].text = text.String()
+	}
+	return out
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// textDecoration returns the CSS text-decoration for a style, if any
+func textDecoration(st Style) string {
+	var parts []string
+	if st.Underline {
+		parts = append(parts, "underline")
+	}
+	if st.Strike {
+		parts = append(parts, "line-through")
+	}
+	return strings.Join(parts, " ")
+}
+
+// HTML writes the screen as a self-contained HTML document with a single
+// <pre> block and inline styles
+func HTML(w io.Writer, s *Screen, opts *Options) error {
+	theme := opts.theme()
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n</head>\n")
+	fmt.Fprintf(bw, "<body style=\"margin:0;background-color:%s\">\n", hexColor(theme.Background))
+	fmt.Fprintf(bw, "<pre style=\"margin:0;padding:%dpx;color:%s;background-color:%s;font-family:'DejaVu Sans Mono',Menlo,Consolas,monospace;font-size:14px;line-height:1.2\">",
+		opts.padding(), hexColor(theme.Foreground), hexColor(theme.Background))
+
+	for i, line := range s.Lines {
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		for _, r := range runs(line) {
+			text := html.EscapeString(r.text)
+			if r.style == (Style{}) {
+				bw.WriteString(text)
+				continue
+			}
+
+			fg, bg := theme.Colors(r.style)
+			var css []string
+			if fg != theme.Foreground {
+				css = append(css, "color:"+hexColor(fg))
+			}
+			if bg != theme.Background {
+				css = append(css, "background-color:"+hexColor(bg))
+			}
+			if r.style.Bold {
+				css = append(css, "font-weight:bold")
+			}
+			if r.style.Italic {
+				css = append(css, "font-style:italic")
+			}
+			if deco := textDecoration(r.style); deco != "" {
+				css = append(css, "text-decoration:"+deco)
+			}
+			if len(css) == 0 {
+				bw.WriteString(text)
+				continue
+			}
+			fmt.Fprintf(bw, "<span style=\"%s\">%s</span>", strings.Join(css, ";"), text)
+		}
+	}
+
+	bw.WriteString("</pre>\n</body>\n</html>\n")
+	return bw.Flush()
+}
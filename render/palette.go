@@ -0,0 +1,85 @@
+package render
+
+import "image/color"
+
+// Theme sets the colors used for default foreground/background and the
+// 16 base palette entries
+type Theme struct {
+	Foreground color.RGBA
+	Background color.RGBA
+	ANSI       [16]color.RGBA
+}
+
+// DefaultTheme is a dark theme close to the xterm defaults
+var DefaultTheme = Theme{
+	Foreground: color.RGBA{0xd0, 0xd0, 0xd0, 0xff},
+	Background: color.RGBA{0x1c, 0x1c, 0x1c, 0xff},
+	ANSI: [16]color.RGBA{
+		{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff},
+		{0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+		{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff},
+		{0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+		{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff},
+		{0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+		{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff},
+		{0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+	},
+}
+
+// RGBA resolves a cell color against the theme. fg selects which default
+// applies to ColorDefault.
+func (t *Theme) RGBA(c Color, fg bool) color.RGBA {
+	switch c.Kind {
+	case ColorRGB:
+		return color.RGBA{c.R, c.G, c.B, 0xff}
+	case ColorIndexed:
+		return t.indexed(c.Index)
+	}
+	if fg {
+		return t.Foreground
+	}
+	return t.Background
+}
+
+// indexed returns the xterm 256-color palette entry
+func (t *Theme) indexed(i uint8) color.RGBA {
+	switch {
+	case i < 16:
+		return t.ANSI[i]
+	case i < 232:
+		i -= 16
+		levels := [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+		return color.RGBA{levels[i/36], levels[(i/6)%6], levels[i%6], 0xff}
+	default:
+		v := 8 + (i-232)*10
+		return color.RGBA{v, v, v, 0xff}
+	}
+}
+
+// Colors returns the resolved foreground and background of a style,
+// applying reverse video and faint intensity.
+func (t *Theme) Colors(st Style) (fg, bg color.RGBA) {
+	fgc := st.FG
+	if st.Bold && fgc.Kind == ColorIndexed && fgc.Index < 8 {
+		// Bold brightens the eight base colors, as most terminals do.
+		fgc.Index += 8
+	}
+	fg = t.RGBA(fgc, true)
+	bg = t.RGBA(st.BG, false)
+	if st.Reverse {
+		fg, bg = bg, fg
+	}
+	if st.Faint {
+		fg = blend(bg, fg, 0x80)
+	}
+	return fg, bg
+}
+
+// blend mixes src over dst with the given alpha (0-255)
+func blend(dst, src color.RGBA, alpha uint8) color.RGBA {
+	a := uint32(alpha)
+	mix := func(d, s uint8) uint8 {
+		return uint8((uint32(d)*(255-a) + uint32(s)*a) / 255)
+	}
+	return color.RGBA{mix(dst.R, src.R), mix(dst.G, src.G), mix(dst.B, src.B), 0xff}
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Options control how a screen is rendered
+type Options struct {
+	// Theme resolves default and palette colors. Defaults to DefaultTheme.
+	Theme *Theme
+	// Padding is the margin in pixels around the cell grid.
+	Padding int
+}
+
+func (o *Options) theme() *Theme {
+	if o == nil || o.Theme == nil {
+		return &DefaultTheme
+	}
+	return o.Theme
+}
+
+func (o *Options) padding() int {
+	if o == nil {
+		return 0
+	}
+	return o.Padding
+}
+
+// Image rasterizes a screen using the embedded font. Each cell is
+// CellWidth×CellHeight pixels.
+func Image(s *Screen, opts *Options) *image.RGBA {
+	theme := opts.theme()
+	pad := opts.padding()
+
+	cols, rows := max(s.Width, 1), max(s.Height, 1)
+	img := image.NewRGBA(image.Rect(0, 0, cols*CellWidth+2*pad, rows*CellHeight+2*pad))
+	fillRect(img, img.Bounds(), theme.Background)
+
+	for y, line := range s.Lines {
+		for x, cell := range line {
+			fg, bg := theme.Colors(cell.Style)
+			origin := image.Pt(pad+x*CellWidth, pad+y*CellHeight)
+			fillRect(img, image.Rectangle{origin, origin.Add(image.Pt(CellWidth, CellHeight))}, bg)
+			if cell.Rune == 0 {
+				continue
+			}
+
+			width := 1
+			if cell.Wide {
+				width = 2
+			}
+			drawCell(img, origin, width, cell.Rune, cell.Style, fg)
+		}
+	}
+	return img
+}
+
+// PNG renders a screen and writes it as a PNG image
+func PNG(w io.Writer, s *Screen, opts *Options) error {
+	return png.Encode(w, Image(s, opts))
+}
+
+// drawCell paints the foreground of a single cell spanning width columns
+func drawCell(img *image.RGBA, origin image.Point, width int, r rune, st Style, fg color.RGBA) {
+	c := &cellPainter{img: img, origin: origin, w: width * CellWidth, fg: fg}
+
+	switch {
+	case r == ' ':
+	case drawBox(c, r), drawBlock(c, r), drawBraille(c, r):
+	default:
+		drawGlyph(c, r, st)
+	}
+
+	if st.Underline {
+		c.fill(0, CellHeight-2, c.w, 1, 0xff)
+	}
+	if st.Strike {
+		c.fill(0, CellHeight/2, c.w, 1, 0xff)
+	}
+}
+
+// cellPainter draws into one cell with coordinates relative to its origin
+type cellPainter struct {
+	img    *image.RGBA
+	origin image.Point
+	w      int
+	fg     color.RGBA
+}
+
+// set blends the foreground color into the pixel at (x, y) with the given
+// coverage
+func (c *cellPainter) set(x, y int, alpha uint8) {
+	if alpha == 0 || x < 0 || y < 0 || x >= c.w || y >= CellHeight {
+		return
+	}
+	px, py := c.origin.X+x, c.origin.Y+y
+	if alpha == 0xff {
+		c.img.SetRGBA(px, py, c.fg)
+		return
+	}
+	c.img.SetRGBA(px, py, blend(c.img.RGBAAt(px, py), c.fg, alpha))
+}
+
+func (c *cellPainter) fill(x, y, w, h int, alpha uint8) {
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			c.set(i, j, alpha)
+		}
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, col color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, col)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+const testView = "\x1b[1;31m<red>\x1b[0m & ┌─┐\n\x1b[44mblue\x1b[0m 世界"
+
+// testRed is the color the theme gives the bold red text of testView
+func testRed() color.RGBA {
+	fg, _ := DefaultTheme.Colors(Style{FG: Color{Kind: ColorIndexed, Index: 1}, Bold: true})
+	return fg
+}
+
+func TestPNG(t *testing.T) {
+	var b bytes.Buffer
+	if err := PNG(&b, Parse(testView), nil); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 11*CellWidth || size.Y != 2*CellHeight {
+		t.Errorf("size = %v, want %dx%d", size, 11*CellWidth, 2*CellHeight)
+	}
+
+	// The first cell of the second line has a blue background, and some
+	// pixels of the first line are drawn in red.
+	blue := DefaultTheme.ANSI[4]
+	if got := color.RGBAModel.Convert(img.At(0, CellHeight)).(color.RGBA); got != blue {
+		t.Errorf("background of cell (0,1) = %v, want %v", got, blue)
+	}
+	red := testRed()
+	found := false
+	for y := 0; y < CellHeight && !found; y++ {
+		for x := 0; x < 5*CellWidth; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)).(color.RGBA) == red {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Error("no red pixels in the first line")
+	}
+}
+
+func TestPNGPadding(t *testing.T) {
+	img := Image(Parse("ab"), &Options{Padding: 8})
+	if size := img.Bounds().Size(); size.X != 2*CellWidth+16 || size.Y != CellHeight+16 {
+		t.Errorf("size = %v, want %dx%d", size, 2*CellWidth+16, CellHeight+16)
+	}
+	bg := DefaultTheme.Background
+	if got := img.RGBAAt(0, 0); got != bg {
+		t.Errorf("padding = %v, want the background %v", got, bg)
+	}
+}
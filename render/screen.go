@@ -0,0 +1,275 @@
+// Package render converts the ANSI output of a canvas view into images and
+// documents that keep colors, attributes and box drawing intact.
+package render
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ColorKind identifies how a Color is specified
+type ColorKind uint8
+
+const (
+	ColorDefault ColorKind = iota // terminal default foreground/background
+	ColorIndexed                  // one of the 256 xterm palette entries
+	ColorRGB                      // 24-bit truecolor
+)
+
+// Color is a terminal color as set by an SGR sequence
+type Color struct {
+	Kind    ColorKind
+	Index   uint8
+	R, G, B uint8
+}
+
+// Style holds the SGR attributes of a cell
+type Style struct {
+	FG, BG    Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Reverse   bool
+	Strike    bool
+}
+
+// Cell is a single character cell of a screen
+type Cell struct {
+	Rune  rune
+	Style Style
+	// Wide is set on the first cell of a double-width character;
+	// the following cell is a continuation with Rune == 0.
+	Wide bool
+}
+
+// Screen is a grid of cells decoded from ANSI text
+type Screen struct {
+	Width  int
+	Height int
+	Lines  [][]Cell
+}
+
+// Parse decodes ANSI text into a Screen. SGR sequences are applied to the
+// cells that follow them; cursor movement and other control sequences are
+// skipped, and lines are padded to the width of the longest line.
+func Parse(s string) *Screen {
+	p := &parser{}
+	p.newLine()
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case 0x1b:
+			i = p.escape(s, i)
+		case '\n':
+			p.newLine()
+			i++
+		case '\r':
+			p.col = 0
+			i++
+		case '\t':
+			next := (p.col/8 + 1) * 8
+			for p.col < next {
+				p.put(' ', 1)
+			}
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			if r < 0x20 || r == 0x7f {
+				continue
+			}
+			p.put(r, runewidth.RuneWidth(r))
+		}
+	}
+
+	// Drop the empty line produced by a trailing newline.
+	if n := len(p.lines); n > 1 && len(p.lines[n-1]) == 0 {
+		p.lines = p.lines[:n-1]
+	}
+
+	screen := &Screen{Lines: p.lines, Height: len(p.lines)}
+	for _, line := range p.lines {
+		if len(line) > screen.Width {
+			screen.Width = len(line)
+		}
+	}
+	for i, line := range screen.Lines {
+		for len(line) < screen.Width {
+			line = append(line, Cell{Rune: ' '})
+		}
+		screen.Lines[i] = line
+	}
+	return screen
+}
+
+// String returns the screen content without any styling
+func (s *Screen) String() string {
+	var b strings.Builder
+	for i, line := range s.Lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.TrimRight(lineText(line), " "))
+	}
+	return b.String()
+}
+
+func lineText(line []Cell) string {
+	var b strings.Builder
+	for _, cell := range line {
+		if cell.Rune != 0 {
+			b.WriteRune(cell.Rune)
+		}
+	}
+	return b.String()
+}
+
+type parser struct {
+	lines [][]Cell
+	col   int
+	style Style
+}
+
+func (p *parser) newLine() {
+	p.lines = append(p.lines, nil)
+	p.col = 0
+}
+
+func (p *parser) put(r rune, width int) {
+	if width <= 0 {
+		// Combining marks and zero-width characters have no cell of their own.
+		return
+	}
+	line := p.lines[len(p.lines)-1]
+	for len(line) < p.col+width {
+		line = append(line, Cell{Rune: ' '})
+	}
+	line[p.col] = Cell{Rune: r, Style: p.style, Wide: width > 1}
+	for i := 1; i < width; i++ {
+		line[p.col+i] = Cell{Style: p.style}
+	}
+	p.lines[len(p.lines)-1] = line
+	p.col += width
+}
+
+// escape consumes the escape sequence starting at s[i] and returns the
+// index of the first byte after it.
+func (p *parser) escape(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+	switch s[i+1] {
+	case '[':
+		j := i + 2
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+			j++
+		}
+		if j >= len(s) {
+			return len(s)
+		}
+		if s[j] == 'm' {
+			p.sgr(s[i+2 : j])
+		}
+		return j + 1
+	case ']', 'P', '_', '^':
+		// OSC and other string sequences end with BEL or ST.
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	default:
+		return i + 2
+	}
+}
+
+func (p *parser) sgr(params string) {
+	if params == "" {
+		p.style = Style{}
+		return
+	}
+	fields := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	codes := make([]int, len(fields))
+	for i, f := range fields {
+		codes[i], _ = strconv.Atoi(f)
+	}
+
+	st := &p.style
+	for i := 0; i < len(codes); i++ {
+		switch c := codes[i]; {
+		case c == 0:
+			*st = Style{}
+		case c == 1:
+			st.Bold = true
+		case c == 2:
+			st.Faint = true
+		case c == 3:
+			st.Italic = true
+		case c == 4:
+			st.Underline = true
+		case c == 7:
+			st.Reverse = true
+		case c == 9:
+			st.Strike = true
+		case c == 22:
+			st.Bold, st.Faint = false, false
+		case c == 23:
+			st.Italic = false
+		case c == 24:
+			st.Underline = false
+		case c == 27:
+			st.Reverse = false
+		case c == 29:
+			st.Strike = false
+		case c >= 30 && c <= 37:
+			st.FG = Color{Kind: ColorIndexed, Index: uint8(c - 30)}
+		case c == 39:
+			st.FG = Color{}
+		case c >= 40 && c <= 47:
+			st.BG = Color{Kind: ColorIndexed, Index: uint8(c - 40)}
+		case c == 49:
+			st.BG = Color{}
+		case c >= 90 && c <= 97:
+			st.FG = Color{Kind: ColorIndexed, Index: uint8(c - 90 + 8)}
+		case c >= 100 && c <= 107:
+			st.BG = Color{Kind: ColorIndexed, Index: uint8(c - 100 + 8)}
+		case c == 38 || c == 48:
+			color, n := extendedColor(codes[i+1:])
+			i += n
+			if c == 38 {
+				st.FG = color
+			} else {
+				st.BG = color
+			}
+		}
+	}
+}
+
+// extendedColor decodes the arguments of a 38/48 SGR code and returns the
+// color along with the number of arguments consumed.
+func extendedColor(args []int) (Color, int) {
+	if len(args) == 0 {
+		return Color{}, 0
+	}
+	switch args[0] {
+	case 5:
+		if len(args) < 2 {
+			return Color{}, len(args)
+		}
+		return Color{Kind: ColorIndexed, Index: uint8(args[1])}, 2
+	case 2:
+		if len(args) < 4 {
+			return Color{}, len(args)
+		}
+		return Color{Kind: ColorRGB, R: uint8(args[1]), G: uint8(args[2]), B: uint8(args[3])}, 4
+	}
+	return Color{}, 1
+}
@@ -0,0 +1,73 @@
+package render
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	bold := Style{Bold: true}
+	red := Style{FG: Color{Kind: ColorIndexed, Index: 1}}
+
+	tests := []struct {
+		name   string
+		in     string
+		text   string
+		width  int
+		height int
+		cells  map[[2]int]Cell // expected cells by line and column
+	}{
+		{name: "plain", in: "hello", text: "hello", width: 5, height: 1},
+		{name: "trailing newline", in: "a\nbc\n", text: "a\nbc", width: 2, height: 2},
+		{name: "padded lines", in: "abc\nd", text: "abc\nd", width: 3, height: 2,
+			cells: map[[2]int]Cell{{1, 2}: {Rune: ' '}}},
+		{name: "bold then reset", in: "\x1b[1ma\x1b[0mb", text: "ab", width: 2, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'a', Style: bold}, {0, 1}: {Rune: 'b'}}},
+		{name: "empty SGR resets", in: "\x1b[31ma\x1b[mb", text: "ab", width: 2, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'a', Style: red}, {0, 1}: {Rune: 'b'}}},
+		{name: "bright colors", in: "\x1b[91;102mx", text: "x", width: 1, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'x', Style: Style{
+				FG: Color{Kind: ColorIndexed, Index: 9},
+				BG: Color{Kind: ColorIndexed, Index: 10},
+			}}}},
+		{name: "256 colors", in: "\x1b[38;5;205mx", text: "x", width: 1, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'x', Style: Style{FG: Color{Kind: ColorIndexed, Index: 205}}}}},
+		{name: "truecolor with colons", in: "\x1b[48:2:1:2:3;1mx", text: "x", width: 1, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'x', Style: Style{BG: Color{Kind: ColorRGB, R: 1, G: 2, B: 3}, Bold: true}}}},
+		{name: "truncated extended color", in: "\x1b[38;5mx", text: "x", width: 1, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'x'}}},
+		{name: "attributes cleared one by one", in: "\x1b[1;3;4;7;9m\x1b[22;23;24;27;29mx", text: "x", width: 1, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: 'x'}}},
+		{name: "wide rune", in: "世x", text: "世x", width: 3, height: 1,
+			cells: map[[2]int]Cell{{0, 0}: {Rune: '世', Wide: true}, {0, 1}: {}, {0, 2}: {Rune: 'x'}}},
+		{name: "combining mark has no cell", in: "é", text: "e", width: 1, height: 1},
+		{name: "tab stops", in: "a\tb", text: "a       b", width: 9, height: 1},
+		{name: "carriage return overwrites", in: "abc\rx", text: "xbc", width: 3, height: 1},
+		{name: "cursor movement skipped", in: "a\x1b[2Kb\x1b[10;20H", text: "ab", width: 2, height: 1},
+		{name: "OSC with BEL", in: "\x1b]0;title\x07a", text: "a", width: 1, height: 1},
+		{name: "OSC with ST", in: "\x1b]8;;http://x\x1b\\a\x1b]8;;\x1b\\", text: "a", width: 1, height: 1},
+		{name: "unterminated CSI", in: "a\x1b[1", text: "a", width: 1, height: 1},
+		{name: "lone escape", in: "a\x1b", text: "a", width: 1, height: 1},
+		{name: "control characters dropped", in: "a\x00\x07b", text: "ab", width: 2, height: 1},
+		{name: "empty", in: "", text: "", width: 0, height: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Parse(tt.in)
+			if got := s.String(); got != tt.text {
+				t.Errorf("text = %q, want %q", got, tt.text)
+			}
+			if s.Width != tt.width || s.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", s.Width, s.Height, tt.width, tt.height)
+			}
+			for _, line := range s.Lines {
+				if len(line) != s.Width {
+					t.Errorf("line of %d cells, want %d", len(line), s.Width)
+				}
+			}
+			for pos, want := range tt.cells {
+				if got := s.Lines[pos[0]][pos[1]]; got != want {
+					t.Errorf("cell %v = %+v, want %+v", pos, got, want)
+				}
+			}
+		})
+	}
+}
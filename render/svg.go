@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// SVG writes the screen as a standalone SVG image on the same cell grid as
+// Image. Each styled span becomes a <text> element stretched to its exact
+// column width so that layout survives font substitution.
+func SVG(w io.Writer, s *Screen, opts *Options) error {
+	theme := opts.theme()
+	pad := opts.padding()
+	cols, rows := max(s.Width, 1), max(s.Height, 1)
+	width, height := cols*CellWidth+2*pad, rows*CellHeight+2*pad
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", hexColor(theme.Background))
+	fmt.Fprintf(bw, "<g font-family=\"'DejaVu Sans Mono',Menlo,Consolas,monospace\" font-size=\"16\" xml:space=\"preserve\">\n")
+
+	for y, line := range s.Lines {
+		top := pad + y*CellHeight
+		for _, r := range runs(line) {
+			fg, bg := theme.Colors(r.style)
+			x := pad + r.col*CellWidth
+			if bg != theme.Background {
+				fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+					x, top, r.cols*CellWidth, CellHeight, hexColor(bg))
+			}
+			if strings.TrimSpace(r.text) == "" && !r.style.Underline && !r.style.Strike {
+				continue
+			}
+
+			attrs := fmt.Sprintf(" fill=\"%s\"", hexColor(fg))
+			if r.style.Bold {
+				attrs += " font-weight=\"bold\""
+			}
+			if r.style.Italic {
+				attrs += " font-style=\"italic\""
+			}
+			if deco := textDecoration(r.style); deco != "" {
+				attrs += fmt.Sprintf(" text-decoration=\"%s\"", deco)
+			}
+			fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" textLength=\"%d\" lengthAdjust=\"spacingAndGlyphs\"%s>%s</text>\n",
+				x, top+CellHeight*3/4, r.cols*CellWidth, attrs, html.EscapeString(r.text))
+		}
+	}
+
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}